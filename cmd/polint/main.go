@@ -0,0 +1,116 @@
+// Command polint checks PO files for common translation mistakes.
+//
+// Usage:
+//
+//	polint [flags] file.po ...
+//
+// It exits with status 1 if any issue is found.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robfig/gettext/po"
+	"github.com/robfig/gettext/po/lint"
+)
+
+var (
+	enable  = flag.String("checks", "", "comma-separated `names` of the checks to run, instead of all of them")
+	disable = flag.String("disable", "", "comma-separated `names` of checks not to run")
+	jsonOut = flag.Bool("json", false, "print the issues as JSON, one object per line")
+	list    = flag.Bool("list", false, "list the available checks and exit")
+)
+
+// result is an issue found in a file, as printed with -json.
+type result struct {
+	Filename string `json:"file"`
+	lint.Issue
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: polint [flags] file.po ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *list {
+		for _, c := range lint.Checks() {
+			fmt.Println(c.Name())
+		}
+		return
+	}
+
+	var found, err = run(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "polint:", err)
+		os.Exit(2)
+	}
+	if found {
+		os.Exit(1)
+	}
+}
+
+// run checks the files and returns true if any issue was found.
+func run(filenames []string) (bool, error) {
+	var checks, err = lint.Select(split(*enable)...)
+	if err != nil {
+		return false, err
+	}
+	var disabled = split(*disable)
+	for _, name := range disabled {
+		if _, ok := lint.Lookup(name); !ok {
+			return false, fmt.Errorf("unknown check %q", name)
+		}
+	}
+	var selected []lint.Check
+	for _, c := range checks {
+		if !contains(disabled, c.Name()) {
+			selected = append(selected, c)
+		}
+	}
+
+	var found bool
+	var enc = json.NewEncoder(os.Stdout)
+	for _, filename := range filenames {
+		var f, err = po.ParseFile(filename)
+		if err != nil {
+			return found, err
+		}
+		for _, issue := range lint.Run(f, selected) {
+			found = true
+			if *jsonOut {
+				if err = enc.Encode(result{filename, issue}); err != nil {
+					return found, err
+				}
+				continue
+			}
+			var pos = filename
+			if len(issue.References) > 0 {
+				pos += ": " + issue.References[0]
+			}
+			fmt.Printf("%s: %v\n", pos, issue)
+		}
+	}
+	return found, nil
+}
+
+// split returns the comma-separated elements of s, or nil if s is empty.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func contains(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
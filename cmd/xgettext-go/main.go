@@ -0,0 +1,135 @@
+// Command xgettext-go extracts the translatable strings of Go programs into a
+// PO template.
+//
+// Usage:
+//
+//	xgettext-go [flags] [file or directory ...]
+//
+// Directories are searched recursively for Go files, skipping test files, and
+// for the templates selected by the -template-ext flag. The current directory
+// is searched if no arguments are given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robfig/gettext/extract"
+)
+
+var (
+	output      = flag.String("o", "", "write the template to `file` instead of the standard output")
+	addComments = flag.String("add-comments", extract.DefaultCommentTag, "extract the comments containing `tag` preceding the translated strings")
+	templateExt = flag.String("template-ext", "", "comma-separated `extensions` of the template files to extract, e.g. .html,.tmpl")
+	keywords    keywordList
+)
+
+func init() {
+	flag.Var(&keywords, "k", "additional `keyword` spec, e.g. NGettext:1,2 or PGettext:1c,2; an empty spec disables the default keywords")
+}
+
+// keywordList collects the keywords given on the command line.
+type keywordList struct {
+	list      []extract.Keyword
+	noDefault bool
+}
+
+func (k *keywordList) String() string {
+	var specs []string
+	for _, keyword := range k.list {
+		specs = append(specs, keyword.String())
+	}
+	return strings.Join(specs, " ")
+}
+
+func (k *keywordList) Set(spec string) error {
+	if spec == "" {
+		k.noDefault = true
+		return nil
+	}
+	var keyword, err = extract.ParseKeyword(spec)
+	if err != nil {
+		return err
+	}
+	k.list = append(k.list, keyword)
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: xgettext-go [flags] [file or directory ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "xgettext-go:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var e = &extract.Extractor{CommentTag: *addComments}
+	if !keywords.noDefault {
+		e.Keywords = append(e.Keywords, extract.DefaultKeywords...)
+	}
+	e.Keywords = append(e.Keywords, keywords.list...)
+	if e.Keywords == nil {
+		e.Keywords = []extract.Keyword{}
+	}
+
+	var exts []string
+	if *templateExt != "" {
+		exts = strings.Split(*templateExt, ",")
+	}
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	for _, arg := range args {
+		var info, err = os.Stat(arg)
+		switch {
+		case err != nil:
+			return err
+		case info.IsDir():
+			if err = e.ExtractGoDir(arg); err == nil && exts != nil {
+				err = e.ExtractTemplateDir(arg, exts...)
+			}
+		case contains(exts, filepath.Ext(arg)):
+			var src []byte
+			if src, err = os.ReadFile(arg); err == nil {
+				err = e.ExtractTemplateFile(arg, string(src))
+			}
+		default:
+			err = e.ExtractGoFile(arg, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if *output == "" || *output == "-" {
+		_, err := e.File().WriteTo(os.Stdout)
+		return err
+	}
+	var f, err = os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if _, err = e.File().WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func contains(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+// Package extract collects the translatable strings of a program into a PO
+// template, as the xgettext tool of GNU gettext does for C.
+package extract
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/gettext/po"
+)
+
+// DefaultCommentTag marks the comments that are extracted for translators.
+const DefaultCommentTag = "TRANSLATORS:"
+
+// Extractor accumulates the messages found in source files. Messages found
+// more than once, with the same msgctxt and msgid, are merged into one with
+// all their references.
+type Extractor struct {
+	// Keywords are the translation functions whose calls are extracted.
+	// DefaultKeywords are used if nil.
+	Keywords []Keyword
+
+	// CommentTag selects the comments preceding a call that are extracted
+	// for translators: those that contain the tag, from the tag onwards.
+	// No comments are extracted if empty.
+	CommentTag string
+
+	msgs  []po.Message
+	index map[string]int // keyed by msgctxt and msgid
+}
+
+// NewExtractor returns an Extractor for the default keywords, extracting the
+// comments tagged with DefaultCommentTag.
+func NewExtractor() *Extractor {
+	return &Extractor{CommentTag: DefaultCommentTag}
+}
+
+// Messages returns the messages extracted so far, in the order they were
+// first found.
+func (e *Extractor) Messages() []po.Message {
+	return e.msgs
+}
+
+// File returns a PO template with the messages extracted so far, and a
+// header with the fields of the templates written by xgettext.
+func (e *Extractor) File() po.File {
+	return po.File{
+		Header: po.Header{
+			{Key: "Project-Id-Version", Value: "PACKAGE VERSION"},
+			{Key: "Report-Msgid-Bugs-To", Value: ""},
+			{Key: "POT-Creation-Date", Value: time.Now().Format(po.HeaderDateFormat)},
+			{Key: "PO-Revision-Date", Value: "YEAR-MO-DA HO:MI+ZONE"},
+			{Key: "Last-Translator", Value: "FULL NAME <EMAIL@ADDRESS>"},
+			{Key: "Language-Team", Value: "LANGUAGE <LL@li.org>"},
+			{Key: "Language", Value: ""},
+			{Key: "MIME-Version", Value: "1.0"},
+			{Key: "Content-Type", Value: "text/plain; charset=UTF-8"},
+			{Key: "Content-Transfer-Encoding", Value: "8bit"},
+			{Key: "Plural-Forms", Value: po.TemplatePluralForms},
+		},
+		Messages: e.msgs,
+	}
+}
+
+func (e *Extractor) keywords() []Keyword {
+	if e.Keywords == nil {
+		return DefaultKeywords
+	}
+	return e.Keywords
+}
+
+// keyword returns the keyword with the given function name.
+func (e *Extractor) keyword(name string) (Keyword, bool) {
+	for _, k := range e.keywords() {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Keyword{}, false
+}
+
+// add records a message found at the given file and line, with the text of
+// the comment preceding it.
+func (e *Extractor) add(ctxt, id, idPlural, filename string, line int, comment string) {
+	if id == "" {
+		// The empty msgid is reserved for the header.
+		return
+	}
+	if e.index == nil {
+		e.index = make(map[string]int)
+	}
+	var key = ctxt + "\x04" + id
+	var i, ok = e.index[key]
+	if !ok {
+		i = len(e.msgs)
+		e.index[key] = i
+		e.msgs = append(e.msgs, po.Message{Ctxt: ctxt, Id: id, Str: []string{""}})
+	}
+
+	var msg = &e.msgs[i]
+	if idPlural != "" && msg.IdPlural == "" {
+		msg.IdPlural = idPlural
+		msg.Str = []string{"", ""}
+	}
+	for _, c := range e.comments(comment) {
+		if !contains(msg.ExtractedComments, c) {
+			msg.ExtractedComments = append(msg.ExtractedComments, c)
+		}
+	}
+	var ref = filename + ":" + strconv.Itoa(line)
+	if !contains(msg.References, ref) {
+		msg.References = append(msg.References, ref)
+	}
+}
+
+// comments returns the lines of the comment from the comment tag onwards.
+func (e *Extractor) comments(text string) []string {
+	if e.CommentTag == "" {
+		return nil
+	}
+	var i = strings.Index(text, e.CommentTag)
+	if i == -1 {
+		return nil
+	}
+	var r []string
+	for _, line := range strings.Split(text[i:], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			r = append(r, line)
+		}
+	}
+	return r
+}
+
+func contains(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
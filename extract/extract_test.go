@@ -0,0 +1,205 @@
+package extract
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/robfig/gettext/po"
+)
+
+func TestParseKeyword(t *testing.T) {
+	var tests = []struct {
+		spec     string
+		expected Keyword
+	}{
+		{"Gettext", Keyword{Name: "Gettext", Id: 1}},
+		{"NGettext:1,2", Keyword{Name: "NGettext", Id: 1, IdPlural: 2}},
+		{"PGettext:1c,2", Keyword{Name: "PGettext", Ctxt: 1, Id: 2}},
+		{"T:2,3c,4", Keyword{Name: "T", Ctxt: 3, Id: 2, IdPlural: 4}},
+	}
+	for _, test := range tests {
+		var actual, err = ParseKeyword(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+		} else if actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.spec, test.expected, actual)
+		}
+		if actual.String() != test.expected.String() {
+			t.Errorf("%s: String() = %q", test.spec, actual.String())
+		}
+	}
+
+	for _, spec := range []string{"", ":1", "T:", "T:0", "T:x", "T:1c", "T:1,2,3", "T:1c,2c,3"} {
+		if _, err := ParseKeyword(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+const goSrc = `package main
+
+import "github.com/robfig/gettext"
+
+func main() {
+	// TRANSLATORS: shown at startup
+	// to greet the user.
+	println(gettext.Gettext("Hello, world"))
+
+	// Not for translators.
+	println(gettext.Gettext("Hello, " + "world"))
+	println(gettext.NGettext("%d file", "%d files", 2)) // ignored
+	println(PGettext("menu", ` + "`Open`" + `))
+	println(tr.NPGettext("menu", "%d item", "%d items", 3))
+	println(gettext.Gettext(name))
+	println(gettext.Gettext(""))
+	println(gettext.NGettext("too few"))
+	println(Sprintf("not a keyword"))
+}
+`
+
+func TestExtractGoFile(t *testing.T) {
+	var e = NewExtractor()
+	if err := e.ExtractGoFile("main.go", goSrc); err != nil {
+		t.Fatal(err)
+	}
+	var expected = []po.Message{
+		{
+			Comment: po.Comment{
+				ExtractedComments: []string{"TRANSLATORS: shown at startup", "to greet the user."},
+				References:        []string{"main.go:8", "main.go:11"},
+			},
+			Id:  "Hello, world",
+			Str: []string{""},
+		},
+		{
+			Comment:  po.Comment{References: []string{"main.go:12"}},
+			Id:       "%d file",
+			IdPlural: "%d files",
+			Str:      []string{"", ""},
+		},
+		{
+			Comment: po.Comment{References: []string{"main.go:13"}},
+			Ctxt:    "menu",
+			Id:      "Open",
+			Str:     []string{""},
+		},
+		{
+			Comment:  po.Comment{References: []string{"main.go:14"}},
+			Ctxt:     "menu",
+			Id:       "%d item",
+			IdPlural: "%d items",
+			Str:      []string{"", ""},
+		},
+	}
+	if !reflect.DeepEqual(e.Messages(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, e.Messages())
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	var e = NewExtractor()
+	if err := e.ExtractGoFile("main.go", goSrc); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := e.File().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var f, err = po.Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.PluralRule != nil {
+		t.Errorf("expected no plural rule, got %v", f.PluralRule)
+	}
+	if f.Header.PluralForms() != po.TemplatePluralForms {
+		t.Errorf("expected %q, got %q", po.TemplatePluralForms, f.Header.PluralForms())
+	}
+	if !reflect.DeepEqual(f.Messages, e.Messages()) {
+		t.Errorf("expected %#v, got %#v", e.Messages(), f.Messages)
+	}
+}
+
+func TestExtractGoFileKeywords(t *testing.T) {
+	var e = &Extractor{Keywords: []Keyword{{Name: "Sprintf", Id: 1}}}
+	if err := e.ExtractGoFile("main.go", goSrc); err != nil {
+		t.Fatal(err)
+	}
+	var msgs = e.Messages()
+	if len(msgs) != 1 || msgs[0].Id != "not a keyword" {
+		t.Errorf("expected only the Sprintf call, got %#v", msgs)
+	}
+}
+
+func TestExtractGoFileError(t *testing.T) {
+	var e = NewExtractor()
+	if err := e.ExtractGoFile("bad.go", "package"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+const templateSrc = `{{define "title"}}{{T "Welcome"}}{{end}}
+<h1>{{template "title"}}</h1>
+{{/* TRANSLATORS: the number of unread messages */}}
+<p>{{TN "%d message" "%d messages" .Count}}</p>
+{{if .User}}<p>{{.T "Hello, %s" .User.Name}}</p>{{else}}{{"Sign in" | T}}{{end}}
+{{range .Items}}{{TP "menu" "Open"}}{{end}}
+{{with .X}}{{T .Name}}{{printf "%s" (T "Welcome")}}{{end}}
+`
+
+func TestExtractTemplateFile(t *testing.T) {
+	var e = &Extractor{
+		Keywords: []Keyword{
+			{Name: "T", Id: 1},
+			{Name: "TN", Id: 1, IdPlural: 2},
+			{Name: "TP", Ctxt: 1, Id: 2},
+		},
+		CommentTag: DefaultCommentTag,
+	}
+	if err := e.ExtractTemplateFile("page.html", templateSrc); err != nil {
+		t.Fatal(err)
+	}
+	var expected = []po.Message{
+		{
+			Comment: po.Comment{References: []string{"page.html:1", "page.html:7"}},
+			Id:      "Welcome",
+			Str:     []string{""},
+		},
+		{
+			Comment: po.Comment{
+				ExtractedComments: []string{"TRANSLATORS: the number of unread messages"},
+				References:        []string{"page.html:4"},
+			},
+			Id:       "%d message",
+			IdPlural: "%d messages",
+			Str:      []string{"", ""},
+		},
+		{
+			Comment: po.Comment{References: []string{"page.html:5"}},
+			Id:      "Hello, %s",
+			Str:     []string{""},
+		},
+		{
+			Comment: po.Comment{References: []string{"page.html:5"}},
+			Id:      "Sign in",
+			Str:     []string{""},
+		},
+		{
+			Comment: po.Comment{References: []string{"page.html:6"}},
+			Ctxt:    "menu",
+			Id:      "Open",
+			Str:     []string{""},
+		},
+	}
+	if !reflect.DeepEqual(e.Messages(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, e.Messages())
+	}
+}
+
+func TestExtractTemplateFileError(t *testing.T) {
+	var e = NewExtractor()
+	if err := e.ExtractTemplateFile("bad.html", "{{if}}"); err == nil {
+		t.Error("expected an error")
+	}
+}
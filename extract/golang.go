@@ -0,0 +1,127 @@
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExtractGoFile extracts the messages of a Go source file. If src is nil, the
+// file is read from filename; otherwise src is parsed as with parser.ParseFile,
+// and filename is only used in references.
+func (e *Extractor) ExtractGoFile(filename string, src interface{}) error {
+	var fset = token.NewFileSet()
+	var file, err = parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// Comments are looked up by the line they end on.
+	var comments = make(map[int]*ast.CommentGroup)
+	for _, group := range file.Comments {
+		comments[fset.Position(group.End()).Line] = group
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		var call, ok = node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var k Keyword
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			k, ok = e.keyword(fun.Name)
+		case *ast.SelectorExpr:
+			k, ok = e.keyword(fun.Sel.Name)
+		}
+		if !ok || len(call.Args) < k.nargs() {
+			return true
+		}
+
+		var ctxt, id, idPlural string
+		if id, ok = goString(call.Args[k.Id-1]); !ok {
+			return true
+		}
+		if k.Ctxt != 0 {
+			if ctxt, ok = goString(call.Args[k.Ctxt-1]); !ok {
+				return true
+			}
+		}
+		if k.IdPlural != 0 {
+			if idPlural, ok = goString(call.Args[k.IdPlural-1]); !ok {
+				return true
+			}
+		}
+
+		var pos = fset.Position(call.Pos())
+		var comment string
+		if group := comments[pos.Line-1]; group != nil {
+			comment = group.Text()
+		} else if group := comments[pos.Line]; group != nil && group.Pos() < call.Pos() {
+			comment = group.Text()
+		}
+		e.add(ctxt, id, idPlural, filepath.ToSlash(filename), pos.Line, comment)
+		return true
+	})
+	return nil
+}
+
+// ExtractGoDir extracts the messages of the Go source files in the directory
+// and its subdirectories, skipping test files.
+func (e *Extractor) ExtractGoDir(dir string) error {
+	return walkDir(dir, func(name string) bool {
+		return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+	}, func(path string) error {
+		return e.ExtractGoFile(path, nil)
+	})
+}
+
+// walkDir calls extract for the files in the directory and its subdirectories
+// whose name is matched, in lexical order. Directories named "testdata" or
+// "vendor" or starting with "." or "_" are skipped, as the go tool does.
+func walkDir(dir string, match func(name string) bool, extract func(path string) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		var name = d.Name()
+		if d.IsDir() {
+			if path != dir && (name == "testdata" || name == "vendor" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !match(name) {
+			return nil
+		}
+		return extract(path)
+	})
+}
+
+// goString returns the value of a constant string expression: a string
+// literal, or a concatenation of them.
+func goString(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		var s, err = strconv.Unquote(expr.Value)
+		return s, err == nil
+	case *ast.ParenExpr:
+		return goString(expr.X)
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		var x, okx = goString(expr.X)
+		var y, oky = goString(expr.Y)
+		return x + y, okx && oky
+	}
+	return "", false
+}
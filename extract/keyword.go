@@ -0,0 +1,93 @@
+package extract
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Keyword describes a function whose calls mark translatable strings, and
+// which of its arguments hold the msgctxt, msgid and msgid_plural.
+type Keyword struct {
+	Name     string // name of the function or method
+	Ctxt     int    // position of the msgctxt argument, starting at 1, or 0 if none
+	Id       int    // position of the msgid argument, starting at 1
+	IdPlural int    // position of the msgid_plural argument, or 0 if none
+}
+
+// DefaultKeywords are the translation functions of the po and gettext
+// packages.
+var DefaultKeywords = []Keyword{
+	{Name: "Gettext", Id: 1},
+	{Name: "NGettext", Id: 1, IdPlural: 2},
+	{Name: "PGettext", Ctxt: 1, Id: 2},
+	{Name: "NPGettext", Ctxt: 1, Id: 2, IdPlural: 3},
+	{Name: "DGettext", Id: 2},
+	{Name: "DNGettext", Id: 2, IdPlural: 3},
+	{Name: "DPGettext", Ctxt: 2, Id: 3},
+	{Name: "DNPGettext", Ctxt: 2, Id: 3, IdPlural: 4},
+}
+
+// ParseKeyword parses a keyword in the syntax of the --keyword option of
+// xgettext: the function name, optionally followed by a colon and the
+// comma-separated positions of the msgid and msgid_plural arguments, with the
+// msgctxt argument marked by a "c" suffix. For example, "Gettext",
+// "NGettext:1,2" or "PGettext:1c,2". The msgid is the first argument if no
+// position is given.
+func ParseKeyword(spec string) (Keyword, error) {
+	var name, args, found = strings.Cut(spec, ":")
+	var k = Keyword{Name: name}
+	if name == "" {
+		return Keyword{}, fmt.Errorf("extract: invalid keyword %q: missing name", spec)
+	}
+	if !found {
+		k.Id = 1
+		return k, nil
+	}
+
+	for _, arg := range strings.Split(args, ",") {
+		var ctxt = strings.HasSuffix(arg, "c")
+		var pos, err = strconv.Atoi(strings.TrimSuffix(arg, "c"))
+		switch {
+		case err != nil || pos < 1:
+			return Keyword{}, fmt.Errorf("extract: invalid keyword %q: bad argument %q", spec, arg)
+		case ctxt && k.Ctxt == 0:
+			k.Ctxt = pos
+		case !ctxt && k.Id == 0:
+			k.Id = pos
+		case !ctxt && k.IdPlural == 0:
+			k.IdPlural = pos
+		default:
+			return Keyword{}, fmt.Errorf("extract: invalid keyword %q: too many arguments", spec)
+		}
+	}
+	if k.Id == 0 {
+		return Keyword{}, fmt.Errorf("extract: invalid keyword %q: missing msgid argument", spec)
+	}
+	return k, nil
+}
+
+// String returns the keyword in the syntax accepted by ParseKeyword.
+func (k Keyword) String() string {
+	var args []string
+	if k.Ctxt != 0 {
+		args = append(args, strconv.Itoa(k.Ctxt)+"c")
+	}
+	args = append(args, strconv.Itoa(k.Id))
+	if k.IdPlural != 0 {
+		args = append(args, strconv.Itoa(k.IdPlural))
+	}
+	return k.Name + ":" + strings.Join(args, ",")
+}
+
+// nargs returns the number of arguments a call needs to provide the strings.
+func (k Keyword) nargs() int {
+	var n = k.Id
+	if k.Ctxt > n {
+		n = k.Ctxt
+	}
+	if k.IdPlural > n {
+		n = k.IdPlural
+	}
+	return n
+}
@@ -0,0 +1,180 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// ExtractTemplateFile extracts the messages of a text/template or
+// html/template file, whose content is src. The keywords are the template
+// functions or methods that translate strings, for example T in
+// {{T "Hello"}} or {{.T "Hello"}}; a string piped into them is their last
+// argument, as in {{"Hello" | T}}. Comments such as {{/* TRANSLATORS: ... */}}
+// are extracted like Go comments.
+func (e *Extractor) ExtractTemplateFile(filename, src string) error {
+	var tree = parse.New(filename)
+	tree.Mode = parse.ParseComments | parse.SkipFuncCheck
+	var trees = make(map[string]*parse.Tree)
+	if _, err := tree.Parse(src, "", "", trees); err != nil {
+		return err
+	}
+
+	var w = templateWalker{e: e, src: src, comments: make(map[int]string)}
+	for _, tree := range trees {
+		w.walk(tree.Root)
+	}
+	// Templates are walked in any order, so the calls are sorted to record
+	// the messages in the order of the file.
+	sort.Slice(w.calls, func(i, j int) bool { return w.calls[i].pos < w.calls[j].pos })
+	for _, call := range w.calls {
+		var line = w.line(call.pos)
+		var comment = w.comments[line-1]
+		if c, ok := w.comments[line]; ok {
+			comment = c
+		}
+		e.add(call.ctxt, call.id, call.idPlural, filepath.ToSlash(filename), line, comment)
+	}
+	return nil
+}
+
+// ExtractTemplateDir extracts the messages of the template files in the
+// directory and its subdirectories that have one of the given extensions,
+// such as ".html" or ".tmpl".
+func (e *Extractor) ExtractTemplateDir(dir string, exts ...string) error {
+	return walkDir(dir, func(name string) bool {
+		return contains(exts, filepath.Ext(name))
+	}, func(path string) error {
+		var src, err = os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return e.ExtractTemplateFile(path, string(src))
+	})
+}
+
+// templateCall is a call of a keyword found in a template.
+type templateCall struct {
+	pos                parse.Pos
+	ctxt, id, idPlural string
+}
+
+// templateWalker finds the keyword calls and comments in the nodes of a
+// template.
+type templateWalker struct {
+	e        *Extractor
+	src      string
+	calls    []templateCall
+	comments map[int]string // keyed by the line they end on
+}
+
+func (w *templateWalker) walk(node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, n := range node.Nodes {
+			w.walk(n)
+		}
+	case *parse.CommentNode:
+		var text = strings.TrimSuffix(strings.TrimPrefix(node.Text, "/*"), "*/")
+		w.comments[w.line(node.Pos)+strings.Count(node.Text, "\n")] = text
+	case *parse.ActionNode:
+		w.walk(node.Pipe)
+	case *parse.TemplateNode:
+		w.walk(node.Pipe)
+	case *parse.IfNode:
+		w.branch(&node.BranchNode)
+	case *parse.RangeNode:
+		w.branch(&node.BranchNode)
+	case *parse.WithNode:
+		w.branch(&node.BranchNode)
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+		var piped parse.Node
+		for _, cmd := range node.Cmds {
+			w.command(cmd, piped)
+			piped = nil
+			if len(cmd.Args) == 1 {
+				piped = cmd.Args[0]
+			}
+		}
+	}
+}
+
+func (w *templateWalker) branch(node *parse.BranchNode) {
+	w.walk(node.Pipe)
+	w.walk(node.List)
+	w.walk(node.ElseList)
+}
+
+// command records the command if it calls a keyword. piped is the argument
+// passed by the previous command of the pipeline, if any.
+func (w *templateWalker) command(cmd *parse.CommandNode, piped parse.Node) {
+	for _, arg := range cmd.Args {
+		w.walk(arg)
+	}
+	if len(cmd.Args) == 0 {
+		return
+	}
+
+	var name string
+	switch fun := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		name = fun.Ident
+	case *parse.FieldNode:
+		name = fun.Ident[len(fun.Ident)-1]
+	case *parse.VariableNode:
+		if len(fun.Ident) < 2 {
+			return
+		}
+		name = fun.Ident[len(fun.Ident)-1]
+	default:
+		return
+	}
+	var k, ok = w.e.keyword(name)
+	if !ok {
+		return
+	}
+	var args = cmd.Args[1:]
+	if piped != nil {
+		args = append(args[:len(args):len(args)], piped)
+	}
+	if len(args) < k.nargs() {
+		return
+	}
+
+	var call = templateCall{pos: cmd.Position()}
+	if call.id, ok = templateString(args[k.Id-1]); !ok {
+		return
+	}
+	if k.Ctxt != 0 {
+		if call.ctxt, ok = templateString(args[k.Ctxt-1]); !ok {
+			return
+		}
+	}
+	if k.IdPlural != 0 {
+		if call.idPlural, ok = templateString(args[k.IdPlural-1]); !ok {
+			return
+		}
+	}
+	w.calls = append(w.calls, call)
+}
+
+// line returns the line number of the position in the source.
+func (w *templateWalker) line(pos parse.Pos) int {
+	return 1 + strings.Count(w.src[:pos], "\n")
+}
+
+// templateString returns the value of a string literal argument.
+func templateString(node parse.Node) (string, bool) {
+	if s, ok := node.(*parse.StringNode); ok {
+		return s.Text, true
+	}
+	return "", false
+}
@@ -0,0 +1,233 @@
+// Package gettext translates messages at runtime into the locales of a Bundle,
+// using catalogs read from PO or MO files by package po.
+//
+// Catalogs are keyed by locale and text domain, and loaded from directories,
+// or from any fs.FS such as an embed.FS, in either of the usual layouts:
+//
+//	LOCALE/LC_MESSAGES/DOMAIN.po
+//	DOMAIN/LOCALE.po
+//
+// A Translator looks up messages in the catalogs of its locale and then of the
+// less specific locales it falls back to, such as "pt" for "pt_BR", until the
+// source language of the messages is reached.
+//
+// Each application or plugin may have its own domain of messages, with its
+// catalogs in its own directory given to Bundle.BindDomain. Domains are
+// looked up with the D variants of the gettext functions, such as DGettext.
+package gettext
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/robfig/gettext/po"
+)
+
+// DefaultDomain is the text domain that translators look up messages in,
+// unless another one is set with Bundle.TextDomain.
+const DefaultDomain = "messages"
+
+// Bundle holds the catalogs of an application, keyed by locale and domain.
+// It is safe for concurrent use: catalogs and domains may be added while
+// translators look up messages. The zero value is an empty Bundle ready to
+// use.
+type Bundle struct {
+	// SourceLanguage is the locale of the msgids, such as "en". Translators
+	// do not fall back to it, or to less specific locales. It must be set
+	// before the bundle is used.
+	SourceLanguage string
+
+	mu       sync.RWMutex
+	domain   string // DefaultDomain if empty
+	catalogs map[catalogKey]*po.Catalog
+}
+
+// catalogKey identifies the catalog of a domain in a locale.
+type catalogKey struct {
+	locale, domain string
+}
+
+// NewBundle returns an empty Bundle.
+func NewBundle() *Bundle {
+	return &Bundle{domain: DefaultDomain, catalogs: make(map[catalogKey]*po.Catalog)}
+}
+
+// TextDomain sets the domain that Gettext, NGettext, PGettext and NPGettext
+// look up messages in, unless domain is empty, and returns the current one.
+func (b *Bundle) TextDomain(domain string) string {
+	if domain == "" {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+		if b.domain == "" {
+			return DefaultDomain
+		}
+		return b.domain
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.domain = domain
+	return domain
+}
+
+// Domains returns the sorted names of the domains with a catalog.
+func (b *Bundle) Domains() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var (
+		r    []string
+		seen = make(map[string]bool)
+	)
+	for key := range b.catalogs {
+		if !seen[key.domain] {
+			seen[key.domain] = true
+			r = append(r, key.domain)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+// AddCatalog adds the catalog of the given locale and domain, replacing any
+// previous one. The locale is canonicalized as po.ParseLocale does.
+func (b *Bundle) AddCatalog(locale, domain string, c *po.Catalog) error {
+	var l, err = po.ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	if b.catalogs == nil {
+		b.catalogs = make(map[catalogKey]*po.Catalog)
+	}
+	b.catalogs[catalogKey{l.String(), domain}] = c
+	b.mu.Unlock()
+	return nil
+}
+
+// AddFile adds the translations of the given file to the catalogs, as
+// AddCatalog does.
+func (b *Bundle) AddFile(locale, domain string, f po.File) error {
+	return b.AddCatalog(locale, domain, po.NewCatalog(f))
+}
+
+// Locales returns the canonical names of the locales with a catalog, in no
+// particular order.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var (
+		r    []string
+		seen = make(map[string]bool)
+	)
+	for key := range b.catalogs {
+		if !seen[key.locale] {
+			seen[key.locale] = true
+			r = append(r, key.locale)
+		}
+	}
+	return r
+}
+
+// Translator returns a translator into the given locale, a POSIX locale name
+// or BCP 47 tag. Messages are returned untranslated for the "C" locale, and
+// for locales that can not be parsed.
+func (b *Bundle) Translator(locale string) *Translator {
+	var t = &Translator{bundle: b, locale: locale}
+	var l, err = po.ParseLocale(locale)
+	if err != nil {
+		return t
+	}
+	var source, _ = po.ParseLocale(b.SourceLanguage)
+	var stop = make(map[string]bool)
+	for _, name := range source.Fallbacks() {
+		stop[name] = true
+	}
+	for _, name := range l.Fallbacks() {
+		if stop[name] {
+			break
+		}
+		t.fallbacks = append(t.fallbacks, name)
+	}
+	return t
+}
+
+// Translator translates messages into a locale, following the semantics of
+// the GNU gettext functions.
+type Translator struct {
+	bundle    *Bundle
+	locale    string
+	fallbacks []string // locales to look up, from the most specific
+}
+
+// Locale returns the locale the translator was requested for.
+func (t *Translator) Locale() string {
+	return t.locale
+}
+
+// Gettext returns the translation of the given msgid.
+func (t *Translator) Gettext(id string) string {
+	return t.NPGettext("", id, "", 1)
+}
+
+// NGettext returns the plural form of the translation appropriate for the
+// quantity n. If there is no translation, id is returned if n == 1, and
+// idPlural otherwise.
+func (t *Translator) NGettext(id, idPlural string, n int) string {
+	return t.NPGettext("", id, idPlural, n)
+}
+
+// PGettext returns the translation of the given msgid in the given context.
+func (t *Translator) PGettext(ctxt, id string) string {
+	return t.NPGettext(ctxt, id, "", 1)
+}
+
+// NPGettext returns the plural form of the translation of the given msgid in
+// the given context appropriate for the quantity n.
+func (t *Translator) NPGettext(ctxt, id, idPlural string, n int) string {
+	return t.DNPGettext(t.bundle.TextDomain(""), ctxt, id, idPlural, n)
+}
+
+// DGettext returns the translation of the given msgid in the given domain.
+func (t *Translator) DGettext(domain, id string) string {
+	return t.DNPGettext(domain, "", id, "", 1)
+}
+
+// DNGettext returns the plural form of the translation in the given domain
+// appropriate for the quantity n.
+func (t *Translator) DNGettext(domain, id, idPlural string, n int) string {
+	return t.DNPGettext(domain, "", id, idPlural, n)
+}
+
+// DPGettext returns the translation of the given msgid in the given domain
+// and context.
+func (t *Translator) DPGettext(domain, ctxt, id string) string {
+	return t.DNPGettext(domain, ctxt, id, "", 1)
+}
+
+// DNPGettext returns the plural form of the translation of the given msgid in
+// the given domain and context appropriate for the quantity n.
+func (t *Translator) DNPGettext(domain, ctxt, id, idPlural string, n int) string {
+	if str, ok := t.bundle.lookup(t.fallbacks, domain, ctxt, id, idPlural, n); ok {
+		return str
+	}
+	if n != 1 && idPlural != "" {
+		return idPlural
+	}
+	return id
+}
+
+// lookup returns the translation of a message in the first of the given
+// locales that has one, and whether one was found.
+func (b *Bundle) lookup(locales []string, domain, ctxt, id, idPlural string, n int) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, locale := range locales {
+		var c, ok = b.catalogs[catalogKey{locale, domain}]
+		if !ok {
+			continue
+		}
+		if str, ok := c.Lookup(ctxt, id, idPlural, n); ok {
+			return str, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,270 @@
+package gettext
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/robfig/gettext/po"
+)
+
+const ptPO = `msgid ""
+msgstr ""
+"Language: pt\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello"
+msgstr "Olá"
+
+msgid "Goodbye"
+msgstr "Adeus"
+
+msgid "file"
+msgid_plural "files"
+msgstr[0] "arquivo"
+msgstr[1] "arquivos"
+`
+
+const ptBRPO = `msgid ""
+msgstr ""
+"Language: pt_BR\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+msgid "Goodbye"
+msgstr "Tchau"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Abrir"
+
+msgid "file"
+msgid_plural "files"
+msgstr[0] "arquivo"
+msgstr[1] "arquivos"
+`
+
+const enGBPO = `msgid ""
+msgstr ""
+"Language: en_GB\n"
+
+msgid "Color"
+msgstr "Colour"
+`
+
+func writeFile(t *testing.T, filename, content string) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// moData returns the PO file content compiled to an MO file.
+func moData(t *testing.T, content string) []byte {
+	var f, err = po.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteMO(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeMO(t *testing.T, filename, content string) {
+	writeFile(t, filename, string(moData(t, content)))
+}
+
+func TestTranslator(t *testing.T) {
+	var dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, "pt", "LC_MESSAGES", "messages.po"), ptPO)
+	writeMO(t, filepath.Join(dir, "pt_BR", "LC_MESSAGES", "messages.mo"), ptBRPO)
+	writeFile(t, filepath.Join(dir, "messages", "en_GB.po"), enGBPO)
+
+	var b = NewBundle()
+	b.SourceLanguage = "en"
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		locale, ctxt, id, idPlural string
+		n                          int
+		expected                   string
+	}{
+		{"pt_BR", "", "Goodbye", "", 1, "Tchau"},
+		{"pt-BR", "", "Hello", "", 1, "Olá"},
+		{"pt_BR.UTF-8", "menu", "Open", "", 1, "Abrir"},
+		{"pt_BR", "", "file", "files", 0, "arquivo"},
+		{"pt", "", "file", "files", 0, "arquivos"},
+		{"pt_PT", "", "Goodbye", "", 1, "Adeus"},
+		{"pt", "menu", "Open", "", 1, "Open"},
+		{"en_GB", "", "Color", "", 1, "Colour"},
+		{"en_US", "", "Color", "", 1, "Color"},
+		{"fr", "", "file", "files", 2, "files"},
+		{"C", "", "Hello", "", 1, "Hello"},
+	}
+	for _, test := range tests {
+		var actual = b.Translator(test.locale).NPGettext(test.ctxt, test.id, test.idPlural, test.n)
+		if actual != test.expected {
+			t.Errorf("%v %q: expected %q, got %q", test.locale, test.id, test.expected, actual)
+		}
+	}
+}
+
+func TestSourceLanguage(t *testing.T) {
+	var b = NewBundle()
+	var f, err = po.Parse(strings.NewReader(enGBPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = b.AddFile("en", DefaultDomain, f); err != nil {
+		t.Fatal(err)
+	}
+	if actual := b.Translator("en_US").Gettext("Color"); actual != "Colour" {
+		t.Errorf("expected %q, got %q", "Colour", actual)
+	}
+	b.SourceLanguage = "en"
+	if actual := b.Translator("en_US").Gettext("Color"); actual != "Color" {
+		t.Errorf("expected %q, got %q", "Color", actual)
+	}
+}
+
+func TestZeroBundle(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(ptPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b Bundle
+	if actual := b.Translator("pt").Gettext("Hello"); actual != "Hello" {
+		t.Errorf("expected %q, got %q", "Hello", actual)
+	}
+	if err = b.AddFile("pt", DefaultDomain, f); err != nil {
+		t.Fatal(err)
+	}
+	if domain := b.TextDomain(""); domain != DefaultDomain {
+		t.Errorf("expected %q, got %q", DefaultDomain, domain)
+	}
+	if actual := b.Translator("pt").Gettext("Hello"); actual != "Olá" {
+		t.Errorf("expected %q, got %q", "Olá", actual)
+	}
+}
+
+func TestLoadDirPrefersPO(t *testing.T) {
+	var dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, "pt", "LC_MESSAGES", "messages.po"), ptPO)
+	writeMO(t, filepath.Join(dir, "pt", "LC_MESSAGES", "messages.mo"), strings.Replace(ptPO, "Olá", "Oi", 1))
+	writeFile(t, filepath.Join(dir, "messages", "README.txt"), "not a catalog")
+	writeFile(t, filepath.Join(dir, "messages", "template.po"), "not a catalog")
+
+	var b = NewBundle()
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if actual := b.Translator("pt").Gettext("Hello"); actual != "Olá" {
+		t.Errorf("expected %q, got %q", "Olá", actual)
+	}
+	if locales := b.Locales(); len(locales) != 1 || locales[0] != "pt" {
+		t.Errorf("expected [pt], got %v", locales)
+	}
+}
+
+const pluginPO = `msgid ""
+msgstr ""
+"Language: pt\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello"
+msgstr "Olá, plugin"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Abrir plugin"
+
+msgid "file"
+msgid_plural "files"
+msgstr[0] "ficheiro"
+msgstr[1] "ficheiros"
+`
+
+func TestDomains(t *testing.T) {
+	var appDir, pluginDir = t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(appDir, "pt", "LC_MESSAGES", "messages.po"), ptPO)
+	writeFile(t, filepath.Join(appDir, "pt", "LC_MESSAGES", "other.po"), pluginPO)
+	writeFile(t, filepath.Join(pluginDir, "pt", "LC_MESSAGES", "plugin.po"), pluginPO)
+	writeFile(t, filepath.Join(pluginDir, "pt_BR.po"), ptBRPO)
+
+	var b = NewBundle()
+	if err := b.BindDomain("messages", appDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.BindDomain("plugin", pluginDir); err != nil {
+		t.Fatal(err)
+	}
+	if domains := b.Domains(); len(domains) != 2 || domains[0] != "messages" || domains[1] != "plugin" {
+		t.Errorf("expected [messages plugin], got %v", domains)
+	}
+
+	var tr = b.Translator("pt_BR")
+	var tests = []struct {
+		actual, expected string
+	}{
+		{tr.Gettext("Hello"), "Olá"},
+		{tr.DGettext("plugin", "Hello"), "Olá, plugin"},
+		{tr.DGettext("plugin", "Goodbye"), "Tchau"},
+		{tr.DGettext("other", "Hello"), "Hello"},
+		{tr.DNGettext("plugin", "file", "files", 2), "arquivos"},
+		{tr.DPGettext("plugin", "menu", "Open"), "Abrir"},
+		{tr.DNPGettext("missing", "", "file", "files", 2), "files"},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+
+	if domain := b.TextDomain("plugin"); domain != "plugin" {
+		t.Errorf("expected %q, got %q", "plugin", domain)
+	}
+	if actual := tr.NGettext("file", "files", 1); actual != "arquivo" {
+		t.Errorf("expected %q, got %q", "arquivo", actual)
+	}
+	if actual := b.Translator("pt").Gettext("Hello"); actual != "Olá, plugin" {
+		t.Errorf("expected %q, got %q", "Olá, plugin", actual)
+	}
+}
+
+func TestConcurrentDomains(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(ptPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b = NewBundle()
+	var tr = b.Translator("pt")
+	var done = make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			for j := 0; j < 100; j++ {
+				var domain = "domain" + strconv.Itoa(i*100+j)
+				if err := b.AddFile("pt", domain, f); err != nil {
+					t.Error(err)
+				}
+				if actual := tr.DGettext(domain, "Hello"); actual != "Olá" {
+					t.Errorf("%v: expected %q, got %q", domain, "Olá", actual)
+				}
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	if domains := b.Domains(); len(domains) != 400 {
+		t.Errorf("expected 400 domains, got %v", len(domains))
+	}
+}
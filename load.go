@@ -0,0 +1,150 @@
+package gettext
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/robfig/gettext/po"
+)
+
+// LoadDir adds the catalogs found in the given directory, as LoadFS does.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir))
+}
+
+// LoadFS adds the catalogs found at the root of the given file system, in
+// either the LOCALE/LC_MESSAGES/DOMAIN.{po,mo} or the DOMAIN/LOCALE.{po,mo}
+// layout. Directories and files not named after a locale are ignored. If there
+// are several files for a locale and domain, such as "pt_BR.po" and
+// "pt-BR.po", a PO file is used rather than an MO file, and the first in
+// lexical order otherwise.
+//
+// Catalogs may be read from an embed.FS, a zip.Reader or any other fs.FS;
+// use fs.Sub for catalogs in a subdirectory.
+func (b *Bundle) LoadFS(fsys fs.FS) error {
+	var files = make(map[catalogKey]string)
+	var matches, err = fs.Glob(fsys, "*/LC_MESSAGES/*")
+	if err != nil {
+		return err
+	}
+	for _, name := range matches {
+		var locale, ok = canonicalLocale(path.Dir(path.Dir(name)))
+		if domain := catalogName(name); ok && domain != "" {
+			addCatalogFile(files, catalogKey{locale, domain}, name)
+		}
+	}
+
+	if matches, err = fs.Glob(fsys, "*/*"); err != nil {
+		return err
+	}
+	for _, name := range matches {
+		var domain = path.Dir(name)
+		if locale, ok := canonicalLocale(catalogName(name)); ok && !isLocaleDir(fsys, domain) {
+			addCatalogFile(files, catalogKey{locale, domain}, name)
+		}
+	}
+	return b.loadFiles(fsys, files)
+}
+
+// BindDomain adds the catalogs of the given domain found in the given
+// directory, as BindDomainFS does.
+func (b *Bundle) BindDomain(domain, dir string) error {
+	return b.BindDomainFS(domain, os.DirFS(dir))
+}
+
+// BindDomainFS adds the catalogs of the given domain found at the root of the
+// given file system, as LOCALE/LC_MESSAGES/DOMAIN.{po,mo} or LOCALE.{po,mo},
+// like the bindtextdomain function of GNU gettext. Catalogs of the domain
+// that were added earlier are kept unless replaced.
+func (b *Bundle) BindDomainFS(domain string, fsys fs.FS) error {
+	var files = make(map[catalogKey]string)
+	var matches, err = fs.Glob(fsys, "*")
+	if err != nil {
+		return err
+	}
+	for _, name := range matches {
+		if locale, ok := canonicalLocale(catalogName(name)); ok {
+			addCatalogFile(files, catalogKey{locale, domain}, name)
+		}
+	}
+
+	if matches, err = fs.Glob(fsys, "*/LC_MESSAGES/*"); err != nil {
+		return err
+	}
+	for _, name := range matches {
+		var locale, ok = canonicalLocale(path.Dir(path.Dir(name)))
+		if ok && catalogName(name) == domain {
+			addCatalogFile(files, catalogKey{locale, domain}, name)
+		}
+	}
+	return b.loadFiles(fsys, files)
+}
+
+// catalogName returns the name of a PO or MO file without its extension, or
+// "" for other files.
+func catalogName(name string) string {
+	var base, ext = path.Base(name), path.Ext(name)
+	if ext != ".po" && ext != ".mo" {
+		return ""
+	}
+	return strings.TrimSuffix(base, ext)
+}
+
+// canonicalLocale returns the canonical name of the given locale, as
+// po.ParseLocale gives it, and whether it is one.
+func canonicalLocale(name string) (string, bool) {
+	var l, err = po.ParseLocale(name)
+	if err != nil || l.Language == "" {
+		return "", false
+	}
+	return l.String(), true
+}
+
+// isLocaleDir reports whether dir has an LC_MESSAGES subdirectory.
+func isLocaleDir(fsys fs.FS, dir string) bool {
+	var fi, err = fs.Stat(fsys, path.Join(dir, "LC_MESSAGES"))
+	return err == nil && fi.IsDir()
+}
+
+// addCatalogFile records the file of the catalog with the given key, unless
+// another was already recorded for it that is a PO file, or that name is not.
+func addCatalogFile(files map[catalogKey]string, key catalogKey, name string) {
+	if prev, ok := files[key]; ok && (path.Ext(prev) == ".po" || path.Ext(name) != ".po") {
+		return
+	}
+	files[key] = name
+}
+
+// loadFiles reads the given catalog files and adds them to the bundle.
+func (b *Bundle) loadFiles(fsys fs.FS, files map[catalogKey]string) error {
+	for key, name := range files {
+		var f, err = readCatalogFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if err = b.AddFile(key.locale, key.domain, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCatalogFile reads a PO or MO file, depending on its extension.
+func readCatalogFile(fsys fs.FS, name string) (po.File, error) {
+	var r, err = fsys.Open(name)
+	if err != nil {
+		return po.File{}, err
+	}
+	defer r.Close()
+	if path.Ext(name) == ".po" {
+		return po.ParseWithOptions(r, po.ParseOptions{Filename: name})
+	}
+	f, err := po.ParseMO(r)
+	if err != nil {
+		return po.File{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return f, nil
+}
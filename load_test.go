@@ -0,0 +1,104 @@
+package gettext
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFS(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"pt/LC_MESSAGES/messages.po":    {Data: []byte(ptPO)},
+		"pt/LC_MESSAGES/messages.mo":    {Data: moData(t, strings.Replace(ptPO, "Olá", "Oi", 1))},
+		"pt_BR/LC_MESSAGES/messages.mo": {Data: moData(t, ptBRPO)},
+		"pt_BR/LC_MESSAGES/README":      {Data: []byte("not a catalog")},
+		"plugin/pt.po":                  {Data: []byte(pluginPO)},
+		"plugin/plugin.pot":             {Data: []byte("not a catalog")},
+	}
+	var b = NewBundle()
+	if err := b.LoadFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	var tr = b.Translator("pt_BR")
+	var tests = []struct {
+		actual, expected string
+	}{
+		{tr.Gettext("Hello"), "Olá"},
+		{tr.Gettext("Goodbye"), "Tchau"},
+		{tr.DGettext("plugin", "Hello"), "Olá, plugin"},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+	if domains := b.Domains(); len(domains) != 2 || domains[0] != "messages" || domains[1] != "plugin" {
+		t.Errorf("expected [messages plugin], got %v", domains)
+	}
+}
+
+func TestBindDomainFS(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"pt/LC_MESSAGES/plugin.po":   {Data: []byte(pluginPO)},
+		"pt/LC_MESSAGES/messages.po": {Data: []byte(ptPO)},
+		"pt_BR.mo":                   {Data: moData(t, ptBRPO)},
+	}
+	var b = NewBundle()
+	if err := b.BindDomainFS("plugin", fsys); err != nil {
+		t.Fatal(err)
+	}
+	if domains := b.Domains(); len(domains) != 1 || domains[0] != "plugin" {
+		t.Errorf("expected [plugin], got %v", domains)
+	}
+	var tr = b.Translator("pt_BR")
+	if actual := tr.DGettext("plugin", "Goodbye"); actual != "Tchau" {
+		t.Errorf("expected %q, got %q", "Tchau", actual)
+	}
+	if actual := tr.DGettext("plugin", "Hello"); actual != "Olá, plugin" {
+		t.Errorf("expected %q, got %q", "Olá, plugin", actual)
+	}
+}
+
+func TestLoadFSError(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"de/LC_MESSAGES/messages.po": {Data: []byte("msgid \"unterminated\n")},
+	}
+	var err = NewBundle().LoadFS(fsys)
+	if err == nil || !strings.Contains(err.Error(), "de/LC_MESSAGES/messages.po") {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+}
+
+func TestLoadFSLocales(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"pt-BR/LC_MESSAGES/messages.po":     {Data: []byte(strings.Replace(ptBRPO, "Tchau", "Tchau!", 1))},
+		"pt_BR/LC_MESSAGES/messages.po":     {Data: []byte(ptBRPO)},
+		"pt_BR/LC_MESSAGES/messages.mo":     {Data: moData(t, ptPO)},
+		"Templates/LC_MESSAGES/messages.po": {Data: []byte("not a catalog")},
+		"plugin/pt-BR.mo":                   {Data: moData(t, pluginPO)},
+		"plugin/pt_BR.po":                   {Data: []byte(ptBRPO)},
+		"plugin/Templates.po":               {Data: []byte("not a catalog")},
+	}
+	var b = NewBundle()
+	if err := b.LoadFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	if locales := b.Locales(); len(locales) != 1 || locales[0] != "pt_BR" {
+		t.Errorf("expected [pt_BR], got %v", locales)
+	}
+	var tr = b.Translator("pt_BR")
+	if actual := tr.Gettext("Goodbye"); actual != "Tchau!" {
+		t.Errorf("expected %q, got %q", "Tchau!", actual)
+	}
+	if actual := tr.DGettext("plugin", "Goodbye"); actual != "Tchau" {
+		t.Errorf("expected %q, got %q", "Tchau", actual)
+	}
+
+	b = NewBundle()
+	if err := b.BindDomainFS("messages", fsys); err != nil {
+		t.Fatal(err)
+	}
+	if actual := b.Translator("pt_BR").Gettext("Goodbye"); actual != "Tchau!" {
+		t.Errorf("expected %q, got %q", "Tchau!", actual)
+	}
+}
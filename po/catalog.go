@@ -0,0 +1,86 @@
+package po
+
+// Catalog provides runtime translation of the messages in a File, following
+// the semantics of the GNU gettext functions: messages that are missing,
+// untranslated, fuzzy or obsolete are returned untranslated.
+type Catalog struct {
+	msgs      map[string]*Message // keyed by context and msgid
+	pluralize PluralSelector
+}
+
+// NewCatalog returns a Catalog for the translated messages in the given file.
+func NewCatalog(f File) *Catalog {
+	var c = &Catalog{
+		msgs:      make(map[string]*Message, len(f.Messages)),
+		pluralize: f.Pluralize,
+	}
+	if c.pluralize == nil {
+		c.pluralize = f.PluralRule.pluralize()
+	}
+	if c.pluralize == nil {
+		c.pluralize = pluralNeq1
+	}
+	for i := range f.Messages {
+		var msg = &f.Messages[i]
+		if msg.Obsolete || msg.HasFlag("fuzzy") {
+			continue
+		}
+		c.msgs[catalogKey(msg.Ctxt, msg.Id)] = msg
+	}
+	return c
+}
+
+// Gettext returns the translation of the given msgid.
+func (c *Catalog) Gettext(id string) string {
+	return c.NPGettext("", id, "", 1)
+}
+
+// NGettext returns the plural form of the translation appropriate for the
+// quantity n. If there is no translation, id is returned if n == 1, and
+// idPlural otherwise.
+func (c *Catalog) NGettext(id, idPlural string, n int) string {
+	return c.NPGettext("", id, idPlural, n)
+}
+
+// PGettext returns the translation of the given msgid in the given context.
+func (c *Catalog) PGettext(ctxt, id string) string {
+	return c.NPGettext(ctxt, id, "", 1)
+}
+
+// NPGettext returns the plural form of the translation of the given msgid in
+// the given context appropriate for the quantity n.
+func (c *Catalog) NPGettext(ctxt, id, idPlural string, n int) string {
+	if str, ok := c.Lookup(ctxt, id, idPlural, n); ok {
+		return str
+	}
+	if n != 1 && idPlural != "" {
+		return idPlural
+	}
+	return id
+}
+
+// Lookup returns the translation for the given message and quantity, and
+// whether one was found, so that callers may fall back to other catalogs.
+func (c *Catalog) Lookup(ctxt, id, idPlural string, n int) (string, bool) {
+	var msg, ok = c.msgs[catalogKey(ctxt, id)]
+	if !ok {
+		return "", false
+	}
+	// Singular lookups of a plural message get msgstr[0], as in GNU gettext.
+	var i = 0
+	if idPlural != "" {
+		i = c.pluralize(n)
+	}
+	if i >= len(msg.Str) || msg.Str[i] == "" {
+		return "", false
+	}
+	return msg.Str[i], true
+}
+
+// catalogKey returns the key identifying a message within a catalog.
+func catalogKey(ctxt, id string) string {
+	if ctxt == "" {
+		return id
+	}
+	return ctxt + moContextSep + id
+}
@@ -0,0 +1,96 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "Hello"
+msgstr "Ahoj"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "egg"
+msgid_plural "eggs"
+msgstr[0] "vajce"
+msgstr[1] "vajcia"
+msgstr[2] "vajec"
+
+msgid "Goodbye"
+msgstr ""
+
+#, fuzzy
+msgid "Maybe"
+msgstr "Možno"
+
+#, fuzzy, c-format
+msgid "Hello %s"
+msgstr "Hallo %d"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c = NewCatalog(f)
+
+	var tests = []struct{ actual, expected string }{
+		{c.Gettext("Hello"), "Ahoj"},
+		{c.Gettext("Open"), "Open"},
+		{c.PGettext("menu", "Open"), "Otvoriť"},
+		{c.PGettext("file", "Open"), "Open"},
+		{c.Gettext("Goodbye"), "Goodbye"},
+		{c.Gettext("Maybe"), "Maybe"},
+		{c.Gettext("Hello %s"), "Hello %s"},
+		{c.Gettext("Missing"), "Missing"},
+		{c.NGettext("egg", "eggs", 1), "vajce"},
+		{c.NGettext("egg", "eggs", 3), "vajcia"},
+		{c.NGettext("egg", "eggs", 5), "vajec"},
+		{c.NGettext("apple", "apples", 1), "apple"},
+		{c.NGettext("apple", "apples", 0), "apples"},
+		{c.NPGettext("menu", "egg", "eggs", 5), "eggs"},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+}
+
+func TestCatalogSingularOfPlural(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Language: ar\n"
+"Plural-Forms: nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);\n"
+
+msgid "book"
+msgid_plural "books"
+msgstr[0] "zero"
+msgstr[1] "one"
+msgstr[2] "two"
+msgstr[3] "few"
+msgstr[4] "many"
+msgstr[5] "other"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c = NewCatalog(f)
+	var tests = []struct{ actual, expected string }{
+		{c.Gettext("book"), "zero"},
+		{c.PGettext("", "book"), "zero"},
+		{c.NGettext("book", "books", 1), "one"},
+		{c.NGettext("book", "books", 0), "zero"},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+}
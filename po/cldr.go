@@ -0,0 +1,381 @@
+package po
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CLDRCategories are the plural categories of the Unicode CLDR, in the order
+// of the plural forms they are given in PO files.
+var CLDRCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// cldrMaxSample is the largest quantity tried to find the categories that
+// integers fall in.
+const cldrMaxSample = 1000
+
+// ParseCLDRPluralRule returns the plural rule described by CLDR plural rules,
+// keyed by category, e.g. {"one": "i = 1 and v = 0 @integer 1", "other": ""}.
+// Rules are written in the syntax of the CLDR, using the operands n, i, v, w,
+// f, t, e and c; samples following '@' are ignored.
+//
+// The plural forms are the categories that integers fall in, in the order of
+// CLDRCategories, so that the rule agrees with the Plural-Forms of GNU gettext
+// for most languages. For example Russian has the forms one, few and many,
+// as "other" only applies to fractions. The Expr of the rule is an equivalent
+// C expression, suitable for a Plural-Forms header.
+func ParseCLDRPluralRule(rules map[string]string) (*PluralRule, error) {
+	var conds = make([]cldrCondition, len(CLDRCategories))
+	for category, rule := range rules {
+		var i = cldrCategoryIndex(category)
+		if i == -1 {
+			return nil, fmt.Errorf("po: unknown CLDR plural category %q", category)
+		}
+		var cond, err = parseCLDRCondition(rule)
+		if err != nil {
+			return nil, err
+		}
+		if category == "other" && cond != nil {
+			return nil, fmt.Errorf("po: CLDR plural category \"other\" must not have a condition")
+		}
+		conds[i] = cond
+	}
+
+	// Find the categories that integers fall in.
+	var reached = make([]bool, len(CLDRCategories))
+	for n := 0; n <= cldrMaxSample; n++ {
+		reached[cldrSelect(conds, intOperands(n))] = true
+	}
+	var r = &PluralRule{cldr: conds}
+	for i, category := range CLDRCategories {
+		if reached[i] {
+			r.Categories = append(r.Categories, category)
+		}
+	}
+	r.NPlurals = len(r.Categories)
+
+	// Build the C expression: a chain of conditions ending with the last form.
+	var expr = strconv.Itoa(r.NPlurals - 1)
+	for i := r.NPlurals - 2; i >= 0; i-- {
+		var c, value, constant = conds[cldrCategoryIndex(r.Categories[i])].cExpr()
+		switch {
+		case !constant:
+			expr = c + " ? " + strconv.Itoa(i) + " : " + expr
+		case value:
+			expr = strconv.Itoa(i)
+		}
+	}
+	r.Expr = "(" + expr + ")"
+
+	var _, selector, err = ParsePluralForms(r.String())
+	if err != nil {
+		return nil, err
+	}
+	r.selector = selector
+	return r, nil
+}
+
+// cldrRuleForLanguage returns the CLDR plural rule for the given language
+// code, or nil if there is none. Codes with a region, such as "pt_PT", fall
+// back to the language, as Locale.Fallbacks does.
+func cldrRuleForLanguage(lang string) *PluralRule {
+	for _, name := range localeFallbacks(lang) {
+		if rules, found := cldrPluralRules[name]; found {
+			var rule, _ = ParseCLDRPluralRule(rules)
+			return rule
+		}
+	}
+	return nil
+}
+
+func cldrCategoryIndex(category string) int {
+	for i, c := range CLDRCategories {
+		if c == category {
+			return i
+		}
+	}
+	return -1
+}
+
+// cldrSelect returns the index of the first category whose condition holds,
+// defaulting to "other".
+func cldrSelect(conds []cldrCondition, ops cldrOperands) int {
+	for i, cond := range conds {
+		if cond != nil && cond.eval(ops) {
+			return i
+		}
+	}
+	return len(CLDRCategories) - 1
+}
+
+// cldrOperands are the operands of a number, as defined by the CLDR.
+type cldrOperands struct {
+	n float64 // absolute value
+	i int64   // integer digits
+	v int64   // number of visible fraction digits, with trailing zeros
+	w int64   // number of visible fraction digits, without trailing zeros
+	f int64   // visible fraction digits, with trailing zeros
+	t int64   // visible fraction digits, without trailing zeros
+	e int64   // exponent in compact decimal notation
+}
+
+// intOperands returns the operands of an integer.
+func intOperands(n int) cldrOperands {
+	if n < 0 {
+		n = -n
+	}
+	return cldrOperands{n: float64(n), i: int64(n)}
+}
+
+func (ops cldrOperands) get(operand byte) int64 {
+	switch operand {
+	case 'i':
+		return ops.i
+	case 'v':
+		return ops.v
+	case 'w':
+		return ops.w
+	case 'f':
+		return ops.f
+	case 't':
+		return ops.t
+	case 'e', 'c':
+		return ops.e
+	}
+	return 0
+}
+
+// cldrCondition is a condition of a CLDR plural rule: a disjunction of
+// conjunctions of relations. It is nil for the empty condition.
+type cldrCondition [][]cldrRelation
+
+// cldrRelation compares an operand, optionally reduced modulo mod, with a
+// list of values and ranges.
+type cldrRelation struct {
+	operand byte
+	mod     int64
+	negate  bool       // the relation is "!="
+	ranges  [][2]int64 // inclusive ranges, with equal bounds for single values
+}
+
+func (c cldrCondition) eval(ops cldrOperands) bool {
+	for _, and := range c {
+		var ok = true
+		for _, rel := range and {
+			ok = ok && rel.eval(ops)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (rel cldrRelation) eval(ops cldrOperands) bool {
+	var in bool
+	if rel.operand == 'n' {
+		var x = ops.n
+		if rel.mod != 0 {
+			x = math.Mod(x, float64(rel.mod))
+		}
+		in = x == math.Trunc(x) && rel.contains(int64(x))
+	} else {
+		var x = ops.get(rel.operand)
+		if rel.mod != 0 {
+			x %= rel.mod
+		}
+		in = rel.contains(x)
+	}
+	return in != rel.negate
+}
+
+func (rel cldrRelation) contains(x int64) bool {
+	for _, r := range rel.ranges {
+		if x >= r[0] && x <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// cExpr returns the condition as a C expression for integer values of n, or
+// its value if it is constant for integers.
+func (c cldrCondition) cExpr() (expr string, value, constant bool) {
+	var ors []string
+	for _, and := range c {
+		var ands []string
+		var holds = true
+		for _, rel := range and {
+			var e, v, k = rel.cExpr()
+			switch {
+			case !k:
+				ands = append(ands, e)
+			case !v:
+				holds = false
+			}
+		}
+		switch {
+		case !holds:
+			continue
+		case len(ands) == 0:
+			return "", true, true
+		}
+		ors = append(ors, strings.Join(ands, " && "))
+	}
+	if len(ors) == 0 {
+		return "", false, true
+	}
+	return strings.Join(ors, " || "), false, false
+}
+
+// cExpr returns the relation as a C expression for integer values of n, for
+// which all fraction digits are zero, or its value if it is constant.
+func (rel cldrRelation) cExpr() (expr string, value, constant bool) {
+	if rel.operand != 'n' && rel.operand != 'i' {
+		return "", rel.eval(cldrOperands{}), true
+	}
+	var x = "n"
+	if rel.mod != 0 {
+		x = "n%" + strconv.FormatInt(rel.mod, 10)
+	}
+	var (
+		terms []string
+		join  = " || "
+	)
+	if rel.negate {
+		join = " && "
+	}
+	for _, r := range rel.ranges {
+		var lo, hi = strconv.FormatInt(r[0], 10), strconv.FormatInt(r[1], 10)
+		switch {
+		case r[0] == r[1] && !rel.negate:
+			terms = append(terms, x+"=="+lo)
+		case r[0] == r[1]:
+			terms = append(terms, x+"!="+lo)
+		case !rel.negate:
+			terms = append(terms, "("+x+">="+lo+" && "+x+"<="+hi+")")
+		default:
+			terms = append(terms, "("+x+"<"+lo+" || "+x+">"+hi+")")
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], false, false
+	}
+	return "(" + strings.Join(terms, join) + ")", false, false
+}
+
+// parseCLDRCondition parses the condition of a CLDR plural rule, ignoring any
+// samples.
+func parseCLDRCondition(rule string) (cldrCondition, error) {
+	var p = cldrParser{pluralParser{input: rule, end: len(rule)}}
+	if i := strings.IndexByte(rule, '@'); i != -1 {
+		p.end = i
+	}
+	if p.peek() == 0 {
+		return nil, nil
+	}
+	var cond cldrCondition
+	for {
+		var and []cldrRelation
+		for {
+			var rel, err = p.relation()
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, rel)
+			if !p.acceptWord("and") {
+				break
+			}
+		}
+		cond = append(cond, and)
+		if !p.acceptWord("or") {
+			break
+		}
+	}
+	if p.peek() != 0 {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:p.end])
+	}
+	return cond, nil
+}
+
+// cldrParser parses the conditions of CLDR plural rules.
+type cldrParser struct {
+	pluralParser
+}
+
+// relation parses operand [% value] (= | !=) range_list.
+func (p *cldrParser) relation() (cldrRelation, error) {
+	var rel cldrRelation
+	switch c := p.peek(); c {
+	case 'n', 'i', 'v', 'w', 'f', 't', 'e', 'c':
+		rel.operand = c
+		p.pos++
+	default:
+		return rel, p.errorf("expected operand")
+	}
+	if p.accept("%") {
+		var mod, err = p.value()
+		if err != nil {
+			return rel, err
+		}
+		if mod == 0 {
+			return rel, p.errorf("modulo by zero")
+		}
+		rel.mod = mod
+	}
+	switch {
+	case p.accept("!="):
+		rel.negate = true
+	case p.accept("="):
+	default:
+		return rel, p.errorf("expected '=' or '!='")
+	}
+	for {
+		var lo, err = p.value()
+		if err != nil {
+			return rel, err
+		}
+		var hi = lo
+		if p.accept("..") {
+			if hi, err = p.value(); err != nil {
+				return rel, err
+			}
+		}
+		rel.ranges = append(rel.ranges, [2]int64{lo, hi})
+		if !p.accept(",") {
+			return rel, nil
+		}
+	}
+}
+
+// value parses a non-negative integer.
+func (p *cldrParser) value() (int64, error) {
+	p.peek()
+	var start = p.pos
+	for p.pos < p.end && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, p.errorf("expected number")
+	}
+	var val, err = strconv.ParseInt(p.input[start:p.pos], 10, 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid number")
+	}
+	return val, nil
+}
+
+// acceptWord consumes the given keyword if it is next in the input.
+func (p *cldrParser) acceptWord(word string) bool {
+	if !p.lookingAt(word) {
+		return false
+	}
+	var next = p.pos + len(word)
+	if next < p.end && p.input[next] >= 'a' && p.input[next] <= 'z' {
+		return false
+	}
+	p.pos = next
+	return true
+}
@@ -0,0 +1,121 @@
+package po
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCLDRPluralRule(t *testing.T) {
+	var tests = []struct {
+		lang       string
+		categories []string
+		expr       string
+		forms      map[int]int // expected form by quantity
+	}{
+		{"ja", []string{"other"}, "(0)", map[int]int{0: 0, 1: 0, 2: 0}},
+		{"en", []string{"one", "other"}, "(n==1 ? 0 : 1)", map[int]int{0: 1, 1: 0, 2: 1}},
+		{"hi", []string{"one", "other"}, "(n==0 || n==1 ? 0 : 1)", map[int]int{0: 0, 1: 0, 2: 1}},
+		{"fr", []string{"one", "other"}, "((n==0 || n==1) ? 0 : 1)", map[int]int{0: 0, 1: 0, 2: 1, 1000000: 1}},
+		{"ru", []string{"one", "few", "many"}, "", map[int]int{1: 0, 2: 1, 5: 2, 11: 2, 21: 0, 22: 1, 112: 2}},
+		{"cy", []string{"zero", "one", "two", "few", "many", "other"}, "",
+			map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 5, 6: 4, 7: 5}},
+		{"mt", []string{"one", "two", "few", "many", "other"}, "",
+			map[int]int{0: 2, 1: 0, 2: 1, 3: 2, 10: 2, 11: 3, 19: 3, 20: 4, 103: 2}},
+		{"gd", []string{"one", "two", "few", "other"}, "",
+			map[int]int{1: 0, 11: 0, 2: 1, 12: 1, 3: 2, 19: 2, 20: 3}},
+		{"he", []string{"one", "two", "other"}, "", map[int]int{1: 0, 2: 1, 10: 2, 20: 2}},
+	}
+	for _, test := range tests {
+		var rule = cldrRuleForLanguage(test.lang)
+		if rule == nil {
+			t.Errorf("%v: no rule", test.lang)
+			continue
+		}
+		if !reflect.DeepEqual(rule.Categories, test.categories) {
+			t.Errorf("%v: expected categories %v, got %v", test.lang, test.categories, rule.Categories)
+		}
+		if rule.NPlurals != len(test.categories) {
+			t.Errorf("%v: expected nplurals=%v, got %v", test.lang, len(test.categories), rule.NPlurals)
+		}
+		if test.expr != "" && rule.Expr != test.expr {
+			t.Errorf("%v: expected expression %q, got %q", test.lang, test.expr, rule.Expr)
+		}
+		for n, expected := range test.forms {
+			if actual := rule.Select(n); actual != expected {
+				t.Errorf("%v: n=%v expected %v, got %v", test.lang, n, expected, actual)
+			}
+		}
+	}
+}
+
+// TestCLDRPluralExpr checks that the generated expressions agree with the CLDR
+// rules for integers.
+func TestCLDRPluralExpr(t *testing.T) {
+	for lang, rules := range cldrPluralRules {
+		var rule, err = ParseCLDRPluralRule(rules)
+		if err != nil {
+			t.Errorf("%v: %v", lang, err)
+			continue
+		}
+		for n := 0; n <= 1000; n++ {
+			var category = CLDRCategories[cldrSelect(rule.cldr, intOperands(n))]
+			if actual := rule.Categories[rule.Select(n)]; actual != category {
+				t.Errorf("%v: n=%v expected %v, got %v (%v)", lang, n, category, actual, rule)
+				break
+			}
+		}
+	}
+}
+
+func TestParseCLDRPluralRuleErrors(t *testing.T) {
+	var tests = []map[string]string{
+		{"single": "n = 1"},
+		{"one": "n = 1", "other": "n = 2"},
+		{"one": "x = 1"},
+		{"one": "n == 1"},
+		{"one": "n = "},
+		{"one": "n % 0 = 1"},
+		{"one": "n = 1 and"},
+		{"one": "n = 1 or or n = 2"},
+		{"one": "n = 1..2.."},
+	}
+	for _, test := range tests {
+		if _, err := ParseCLDRPluralRule(test); err == nil {
+			t.Errorf("%v: expected an error", test)
+		}
+	}
+
+	// Samples are ignored.
+	var rule, err = ParseCLDRPluralRule(map[string]string{
+		"one":   "i = 1 and v = 0 @integer 1",
+		"other": " @integer 0, 2~16, 100, 1000, … @decimal 0.0~1.5, …",
+	})
+	if err != nil || rule.NPlurals != 2 {
+		t.Errorf("unexpected result %v, %v", rule, err)
+	}
+}
+
+func TestPluralSelectorForLanguageCLDR(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		n        int
+		expected int
+	}{
+		{"cy", 6, 4},
+		{"mt", 11, 3},
+		{"gd", 12, 1},
+		{"hi", 0, 0},
+		{"hi_IN", 5, 1},
+		{"pt-PT", 0, 1},
+	}
+	for _, test := range tests {
+		var selector = PluralSelectorForLanguage(test.lang)
+		if selector == nil {
+			t.Errorf("%v: no selector", test.lang)
+			continue
+		}
+		if actual := selector(test.n); actual != test.expected {
+			t.Errorf("%v: n=%v expected %v, got %v", test.lang, test.n, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package po
+
+import "strings"
+
+// cldrManyMillions is the "many" rule of the Romance languages, which applies
+// to millions in compact notation, e.g. "1 million de".
+const cldrManyMillions = "e = 0 and i != 0 and i % 1000000 = 0 and v = 0 or e != 0..5"
+
+// cldrPluralRules are the cardinal plural rules of the Unicode CLDR (version
+// 44), by language code.
+var cldrPluralRules = expandCLDRPluralRules([]struct {
+	langs string
+	rules map[string]string
+}{
+	{"bm bo dz hnj id ig ii in ja jbo jv jw kde kea km ko lkt lo ms my nqo osa sah ses sg su th to tpi vi wo yo yue zh", map[string]string{
+		"other": "",
+	}},
+	{"am as bn doi fa gu hi kn pcm zu", map[string]string{
+		"one":   "i = 0 or n = 1",
+		"other": "",
+	}},
+	{"ff hy kab", map[string]string{
+		"one":   "i = 0,1",
+		"other": "",
+	}},
+	{"ast de en et fi fy gl ia io ji lij nl sc sv sw ur yi", map[string]string{
+		"one":   "i = 1 and v = 0",
+		"other": "",
+	}},
+	{"si", map[string]string{
+		"one":   "n = 0,1 or i = 0 and f = 1",
+		"other": "",
+	}},
+	{"ak bho csw guw ln mg nso pa ti wa", map[string]string{
+		"one":   "n = 0..1",
+		"other": "",
+	}},
+	{"tzm", map[string]string{
+		"one":   "n = 0..1 or n = 11..99",
+		"other": "",
+	}},
+	{"af an asa az bal bem bez bg brx ce cgg chr ckb dv ee el eo eu fo fur gsw ha haw hu jgo jmc ka kaj kcg kk kkj kl ks ksb ku ky lb lg mas mgo ml mn mr nah nb nd ne nn nnh no nr ny nyn om or os pap ps rm rof rwk saq sd sdh seh sn so sq ss ssy st syr ta te teo tig tk tn tr ts ug uz ve vo vun wae xh xog", map[string]string{
+		"one":   "n = 1",
+		"other": "",
+	}},
+	{"da", map[string]string{
+		"one":   "n = 1 or t != 0 and i = 0,1",
+		"other": "",
+	}},
+	{"is", map[string]string{
+		"one":   "t = 0 and i % 10 = 1 and i % 100 != 11 or t % 10 = 1 and t % 100 != 11",
+		"other": "",
+	}},
+	{"mk", map[string]string{
+		"one":   "v = 0 and i % 10 = 1 and i % 100 != 11 or f % 10 = 1 and f % 100 != 11",
+		"other": "",
+	}},
+	{"ceb fil tl", map[string]string{
+		"one":   "v = 0 and i = 1,2,3 or v = 0 and i % 10 != 4,6,9 or v != 0 and f % 10 != 4,6,9",
+		"other": "",
+	}},
+	{"lv prg", map[string]string{
+		"zero":  "n % 10 = 0 or n % 100 = 11..19 or v = 2 and f % 100 = 11..19",
+		"one":   "n % 10 = 1 and n % 100 != 11 or v = 2 and f % 10 = 1 and f % 100 != 11 or v != 2 and f % 10 = 1",
+		"other": "",
+	}},
+	{"lag", map[string]string{
+		"zero":  "n = 0",
+		"one":   "i = 0,1 and n != 0",
+		"other": "",
+	}},
+	{"ksh", map[string]string{
+		"zero":  "n = 0",
+		"one":   "n = 1",
+		"other": "",
+	}},
+	{"he iw", map[string]string{
+		"one":   "i = 1 and v = 0 or i = 0 and v != 0",
+		"two":   "i = 2 and v = 0",
+		"other": "",
+	}},
+	{"iu naq sat se sma smi smj smn sms", map[string]string{
+		"one":   "n = 1",
+		"two":   "n = 2",
+		"other": "",
+	}},
+	{"shi", map[string]string{
+		"one":   "i = 0 or n = 1",
+		"few":   "n = 2..10",
+		"other": "",
+	}},
+	{"mo ro", map[string]string{
+		"one":   "i = 1 and v = 0",
+		"few":   "v != 0 or n = 0 or n != 1 and n % 100 = 1..19",
+		"other": "",
+	}},
+	{"bs hr sh sr", map[string]string{
+		"one":   "v = 0 and i % 10 = 1 and i % 100 != 11 or f % 10 = 1 and f % 100 != 11",
+		"few":   "v = 0 and i % 10 = 2..4 and i % 100 != 12..14 or f % 10 = 2..4 and f % 100 != 12..14",
+		"other": "",
+	}},
+	{"fr", map[string]string{
+		"one":   "i = 0,1",
+		"many":  cldrManyMillions,
+		"other": "",
+	}},
+	{"pt", map[string]string{
+		"one":   "i = 0..1",
+		"many":  cldrManyMillions,
+		"other": "",
+	}},
+	{"ca it pt_PT vec", map[string]string{
+		"one":   "i = 1 and v = 0",
+		"many":  cldrManyMillions,
+		"other": "",
+	}},
+	{"es", map[string]string{
+		"one":   "n = 1",
+		"many":  cldrManyMillions,
+		"other": "",
+	}},
+	{"gd", map[string]string{
+		"one":   "n = 1,11",
+		"two":   "n = 2,12",
+		"few":   "n = 3..10,13..19",
+		"other": "",
+	}},
+	{"sl", map[string]string{
+		"one":   "v = 0 and i % 100 = 1",
+		"two":   "v = 0 and i % 100 = 2",
+		"few":   "v = 0 and i % 100 = 3..4 or v != 0",
+		"other": "",
+	}},
+	{"dsb hsb", map[string]string{
+		"one":   "v = 0 and i % 100 = 1 or f % 100 = 1",
+		"two":   "v = 0 and i % 100 = 2 or f % 100 = 2",
+		"few":   "v = 0 and i % 100 = 3..4 or f % 100 = 3..4",
+		"other": "",
+	}},
+	{"cs sk", map[string]string{
+		"one":   "i = 1 and v = 0",
+		"few":   "i = 2..4 and v = 0",
+		"many":  "v != 0",
+		"other": "",
+	}},
+	{"pl", map[string]string{
+		"one":   "i = 1 and v = 0",
+		"few":   "v = 0 and i % 10 = 2..4 and i % 100 != 12..14",
+		"many":  "v = 0 and i != 1 and i % 10 = 0..1 or v = 0 and i % 10 = 5..9 or v = 0 and i % 100 = 12..14",
+		"other": "",
+	}},
+	{"be", map[string]string{
+		"one":   "n % 10 = 1 and n % 100 != 11",
+		"few":   "n % 10 = 2..4 and n % 100 != 12..14",
+		"many":  "n % 10 = 0 or n % 10 = 5..9 or n % 100 = 11..14",
+		"other": "",
+	}},
+	{"lt", map[string]string{
+		"one":   "n % 10 = 1 and n % 100 != 11..19",
+		"few":   "n % 10 = 2..9 and n % 100 != 11..19",
+		"many":  "f != 0",
+		"other": "",
+	}},
+	{"ru uk", map[string]string{
+		"one":   "v = 0 and i % 10 = 1 and i % 100 != 11",
+		"few":   "v = 0 and i % 10 = 2..4 and i % 100 != 12..14",
+		"many":  "v = 0 and i % 10 = 0 or v = 0 and i % 10 = 5..9 or v = 0 and i % 100 = 11..14",
+		"other": "",
+	}},
+	{"br", map[string]string{
+		"one":   "n % 10 = 1 and n % 100 != 11,71,91",
+		"two":   "n % 10 = 2 and n % 100 != 12,72,92",
+		"few":   "n % 10 = 3..4,9 and n % 100 != 10..19,70..79,90..99",
+		"many":  "n != 0 and n % 1000000 = 0",
+		"other": "",
+	}},
+	{"mt", map[string]string{
+		"one":   "n = 1",
+		"two":   "n = 2",
+		"few":   "n = 0 or n % 100 = 3..10",
+		"many":  "n % 100 = 11..19",
+		"other": "",
+	}},
+	{"ga", map[string]string{
+		"one":   "n = 1",
+		"two":   "n = 2",
+		"few":   "n = 3..6",
+		"many":  "n = 7..10",
+		"other": "",
+	}},
+	{"gv", map[string]string{
+		"one":   "v = 0 and i % 10 = 1",
+		"two":   "v = 0 and i % 10 = 2",
+		"few":   "v = 0 and i % 100 = 0,20,40,60,80",
+		"many":  "v != 0",
+		"other": "",
+	}},
+	{"kw", map[string]string{
+		"zero":  "n = 0",
+		"one":   "n = 1",
+		"two":   "n % 100 = 2,22,42,62,82 or n % 1000 = 0 and n % 100000 = 1000..20000,40000,60000,80000 or n != 0 and n % 1000000 = 100000",
+		"few":   "n % 100 = 3,23,43,63,83",
+		"many":  "n != 1 and n % 100 = 1,21,41,61,81",
+		"other": "",
+	}},
+	{"ar ars", map[string]string{
+		"zero":  "n = 0",
+		"one":   "n = 1",
+		"two":   "n = 2",
+		"few":   "n % 100 = 3..10",
+		"many":  "n % 100 = 11..99",
+		"other": "",
+	}},
+	{"cy", map[string]string{
+		"zero":  "n = 0",
+		"one":   "n = 1",
+		"two":   "n = 2",
+		"few":   "n = 3",
+		"many":  "n = 6",
+		"other": "",
+	}},
+})
+
+func expandCLDRPluralRules(groups []struct {
+	langs string
+	rules map[string]string
+}) map[string]map[string]string {
+	var r = make(map[string]map[string]string)
+	for _, group := range groups {
+		for _, lang := range strings.Fields(group.langs) {
+			r[lang] = group.rules
+		}
+	}
+	return r
+}
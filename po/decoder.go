@@ -0,0 +1,130 @@
+package po
+
+import "io"
+
+// Decoder reads the messages of a PO file one at a time, so that large files
+// can be processed without holding every message in memory.
+type Decoder struct {
+	scan      *scanner
+	started   bool     // the first message has been read
+	first     *Message // the first message, if it was not the header
+	header    Header
+	pluralize PluralSelector
+	rule      *PluralRule
+	err       error
+
+	headerComment Comment
+}
+
+// NewDecoder returns a Decoder reading a PO file from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, ParseOptions{})
+}
+
+// NewDecoderWithOptions returns a Decoder reading a PO file from r as directed
+// by opts.
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions) *Decoder {
+	return &Decoder{scan: newScanner(r, opts)}
+}
+
+// Header returns the header of the file, which is empty if the file has none.
+// It may be called at any time.
+func (d *Decoder) Header() (Header, error) {
+	d.start()
+	return d.header, d.headerErr()
+}
+
+// HeaderComment returns the comments of the header entry of the file.
+func (d *Decoder) HeaderComment() (Comment, error) {
+	d.start()
+	return d.headerComment, d.headerErr()
+}
+
+// Pluralize returns the plural function selected by the header of the file.
+func (d *Decoder) Pluralize() (PluralSelector, error) {
+	d.start()
+	return d.pluralize, d.headerErr()
+}
+
+// PluralRule returns the plural rule selected by the header of the file, or
+// nil if it is not known.
+func (d *Decoder) PluralRule() (*PluralRule, error) {
+	d.start()
+	return d.rule, d.headerErr()
+}
+
+// headerErr returns the error encountered reading the header, if any. Reaching
+// the end of a file without messages is not an error.
+func (d *Decoder) headerErr() error {
+	if d.err == io.EOF {
+		return nil
+	}
+	return d.err
+}
+
+// Next returns the next message of the file, not including the header.
+// It returns io.EOF when there are no more messages.
+func (d *Decoder) Next() (Message, error) {
+	d.start()
+	if d.err != nil {
+		return Message{}, d.err
+	}
+	if d.first != nil {
+		var msg = *d.first
+		d.first = nil
+		return msg, nil
+	}
+	var msg, ok = d.read()
+	if !ok {
+		return Message{}, d.err
+	}
+	return msg, nil
+}
+
+// start reads the first message, to find the header.
+func (d *Decoder) start() {
+	if d.started {
+		return
+	}
+	d.started = true
+	var msg, ok = d.read()
+	switch {
+	case !ok:
+		return
+	case isHeader(msg):
+		d.header, d.headerComment = ParseHeader(msg.Str[0]), msg.Comment
+	default:
+		d.first = &msg
+	}
+	var rule, err = headerPluralRule(d.header)
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.pluralize, d.rule = rule.pluralize(), rule
+}
+
+// read reads the next message. If there is none, it returns false and sets
+// err to io.EOF or the error encountered.
+func (d *Decoder) read() (Message, bool) {
+	var scan = d.scan
+	for scan.nextmsg() {
+		// NOTE: the source code order of these fields is important.
+		var msg = Message{
+			Comment:  scan.comment(),
+			Obsolete: scan.isObsolete(),
+			Ctxt:     scan.quo("msgctxt"),
+			Id:       scan.quo("msgid"),
+			IdPlural: scan.quo("msgid_plural"),
+			Str:      scan.msgstr(),
+		}
+		if scan.endmsg() && scan.Err() == nil {
+			return msg, true
+		}
+	}
+	d.err = scan.Err()
+	if d.err == nil {
+		d.err = io.EOF
+	}
+	return Message{}, false
+}
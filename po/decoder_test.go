@@ -0,0 +1,56 @@
+package po
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	var dec = NewDecoder(strings.NewReader(po))
+	var header, err = dec.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(file.Header, header) {
+		t.Errorf("expected header:\n%v\ngot header:\n%v", file.Header, header)
+	}
+	if pluralize, err := dec.Pluralize(); err != nil || pluralize(3) != 1 {
+		t.Errorf("unexpected plural selector: %v", err)
+	}
+	for i, expected := range file.Messages {
+		var msg, err = dec.Next()
+		if err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(expected, msg) {
+			t.Errorf("message %d: expected %v, got %v", i, expected, msg)
+		}
+	}
+	if _, err = dec.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoderNoHeader(t *testing.T) {
+	var dec = NewDecoder(strings.NewReader("msgid \"a\"\nmsgstr \"b\"\n\nmsgid \"c\"\nmsgstr \"d\\q\"\n"))
+	var msg, err = dec.Next()
+	if err != nil || msg.Id != "a" {
+		t.Fatalf("expected message a, got %v, %v", msg, err)
+	}
+	if header, err := dec.Header(); err != nil || header != nil {
+		t.Errorf("expected no header, got %v, %v", header, err)
+	}
+	if _, err = dec.Next(); err == nil || err == io.EOF {
+		t.Errorf("expected parse error, got %v", err)
+	}
+
+	dec = NewDecoder(strings.NewReader(""))
+	if header, err := dec.Header(); err != nil || header != nil {
+		t.Errorf("expected no header, got %v, %v", header, err)
+	}
+	if _, err = dec.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
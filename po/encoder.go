@@ -0,0 +1,47 @@
+package po
+
+import "io"
+
+// Encoder writes a PO file one entry at a time, directly to the destination
+// writer. Writes are not buffered, so wrap slow destinations such as files in
+// a bufio.Writer.
+type Encoder struct {
+	wr writer
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return NewEncoderWithOptions(w, WriteOptions{})
+}
+
+// NewEncoderWithOptions returns an Encoder writing to w, formatted as directed
+// by opts.
+func NewEncoderWithOptions(w io.Writer, opts WriteOptions) *Encoder {
+	return &Encoder{newWriter(w, opts)}
+}
+
+// WriteHeader writes the header entry, which should come first. Untranslated
+// plural messages written afterwards get as many empty msgstr[n] as the
+// plural rule of the header calls for.
+func (e *Encoder) WriteHeader(h Header) error {
+	return e.WriteHeaderWithComment(h, Comment{})
+}
+
+// WriteHeaderWithComment writes the header entry preceded by its comments, as
+// WriteHeader does.
+func (e *Encoder) WriteHeaderWithComment(h Header, c Comment) error {
+	if rule, _ := headerPluralRule(h); rule != nil {
+		e.wr.nplurals = rule.NPlurals
+	}
+	e.wr.header(h, c)
+	e.wr.newline()
+	return e.wr.err
+}
+
+// WriteMessage writes a message, followed by a blank line.
+// Once a write fails, the error is returned by every later call.
+func (e *Encoder) WriteMessage(m Message) error {
+	e.wr.message(m)
+	e.wr.newline()
+	return e.wr.err
+}
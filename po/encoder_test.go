@@ -0,0 +1,55 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	var enc = NewEncoder(&buf)
+	if err := enc.WriteHeader(file.Header); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range file.Messages {
+		if err := enc.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.String() != po {
+		t.Errorf("expected:\n%v\ngot:\n%v", po, buf.String())
+	}
+}
+
+// failingWriter accepts limit bytes, then fails.
+type failingWriter struct {
+	limit int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		var n = w.limit
+		w.limit = 0
+		return n, errWriteFailed
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestEncoderError(t *testing.T) {
+	var enc = NewEncoder(&failingWriter{limit: 20})
+	if err := enc.WriteHeader(file.Header); err != errWriteFailed {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if err := enc.WriteMessage(file.Messages[0]); err != errWriteFailed {
+		t.Errorf("expected write error to persist, got %v", err)
+	}
+
+	var n, err = file.WriteTo(&failingWriter{limit: 100})
+	if err != errWriteFailed || n != 100 {
+		t.Errorf("expected write error after 100 bytes, got %v, %v", n, err)
+	}
+}
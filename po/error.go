@@ -0,0 +1,57 @@
+package po
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrMessageTooLong is reported when a message exceeds
+// ParseOptions.MaxMessageSize.
+var ErrMessageTooLong = errors.New("po: message too long")
+
+// ParseError describes a problem found while parsing a PO file.
+type ParseError struct {
+	Filename string // name of the file, if known
+	Line     int    // line number, starting at 1
+	Column   int    // column of the problem in bytes, starting at 1
+	Text     string // content of the offending line
+	Field    string // field being read, such as "msgid" or "msgstr[2]"
+	Err      error  // the underlying error
+}
+
+func (e *ParseError) Error() string {
+	var pos = fmt.Sprintf("line %d:%d", e.Line, e.Column)
+	if e.Filename != "" {
+		pos = fmt.Sprintf("%s:%d:%d", e.Filename, e.Line, e.Column)
+	}
+	if e.Field != "" {
+		pos += ": " + e.Field
+	}
+	return fmt.Sprintf("%s: %v: %q", pos, e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// unquoteErrPos returns the byte offset within the quoted string str of the
+// problem that makes it fail to unquote.
+func unquoteErrPos(str string) int {
+	if len(str) == 0 || str[0] != '"' {
+		return 0
+	}
+	var s = str[1:]
+	for len(s) > 0 && s[0] != '"' {
+		var _, _, tail, err = strconv.UnquoteChar(s, '"')
+		if err != nil {
+			break
+		}
+		s = tail
+	}
+	if len(s) > 0 && s[0] == '"' {
+		// Anything after the closing quote is the problem.
+		s = s[1:]
+	}
+	return len(str) - len(s)
+}
@@ -0,0 +1,505 @@
+package po
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatError describes a translation whose format directives do not match
+// those of its msgid.
+type FormatError struct {
+	Id     string // msgid of the message
+	Format string // format flag of the message, such as "c-format"
+	Field  string // field with the problem, such as "msgstr" or "msgstr[1]"
+	Err    error  // the underlying error
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s: %s: %v: %q", e.Format, e.Field, e.Err, e.Id)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// formatArg is an argument consumed by a format directive.
+type formatArg struct {
+	key       string // argument number or name
+	typ       string // type of the argument, or "" if any type is accepted
+	directive string // text of the directive
+}
+
+// formatParsers parse the directives of the formats that can be checked.
+var formatParsers = map[string]func(string) ([]formatArg, error){
+	"c-format":            parseCFormat,
+	"go-format":           parseGoFormat,
+	"python-format":       parsePythonFormat,
+	"python-brace-format": parsePythonBraceFormat,
+}
+
+// CheckFormat checks that the format directives of each translation match
+// those of the msgid, for messages flagged "c-format", "go-format",
+// "python-format" or "python-brace-format". Every argument of the msgid must
+// be used with the same type and no other, although positional arguments
+// such as "%[2]d" or "%2$d" may be reordered. In the msgstr of plural
+// messages, which is compared to the msgid_plural, arguments may be omitted,
+// e.g. a translation of "%d files" for n == 1 may read "one file".
+// Empty translations are not checked.
+func (m Message) CheckFormat() []*FormatError {
+	var format string
+	for _, flag := range m.Flags {
+		if formatParsers[flag] != nil {
+			format = flag
+			break
+		}
+	}
+	if format == "" {
+		return nil
+	}
+	var parse = formatParsers[format]
+
+	var id, idField = m.Id, "msgid"
+	if m.IdPlural != "" {
+		id, idField = m.IdPlural, "msgid_plural"
+	}
+	var want, err = parse(id)
+	if err != nil {
+		return []*FormatError{{m.Id, format, idField, err}}
+	}
+
+	var errs []*FormatError
+	for i, str := range m.Str {
+		if str == "" {
+			continue
+		}
+		var field = "msgstr"
+		if m.IdPlural != "" {
+			field = "msgstr[" + strconv.Itoa(i) + "]"
+		}
+		var got, err = parse(str)
+		if err == nil {
+			err = compareFormatArgs(want, got, idField, m.IdPlural != "")
+		}
+		if err != nil {
+			errs = append(errs, &FormatError{m.Id, format, field, err})
+		}
+	}
+	return errs
+}
+
+// compareFormatArgs returns an error describing the first difference between
+// the arguments of the msgid and those of a translation.
+func compareFormatArgs(want, got []formatArg, idField string, plural bool) error {
+	var wantArgs, err = formatArgMap(want)
+	if err != nil {
+		return err
+	}
+	gotArgs, err := formatArgMap(got)
+	if err != nil {
+		return err
+	}
+	for _, arg := range want {
+		var other, ok = gotArgs[arg.key]
+		switch {
+		case !ok && !plural:
+			return fmt.Errorf("missing argument %s, as in %q of %s", arg.key, arg.directive, idField)
+		case ok && arg.typ != other.typ:
+			return fmt.Errorf("argument %s is %q, unlike %q of %s", arg.key, other.directive, arg.directive, idField)
+		}
+	}
+	for _, arg := range got {
+		if _, ok := wantArgs[arg.key]; !ok {
+			return fmt.Errorf("argument %s of %q does not exist in %s", arg.key, arg.directive, idField)
+		}
+	}
+	return nil
+}
+
+// formatArgMap returns the arguments by key, checking that each argument is
+// used with a single type.
+func formatArgMap(args []formatArg) (map[string]formatArg, error) {
+	var r = make(map[string]formatArg, len(args))
+	for _, arg := range args {
+		if prev, ok := r[arg.key]; ok && prev.typ != arg.typ {
+			return nil, fmt.Errorf("argument %s is used as both %q and %q", arg.key, prev.directive, arg.directive)
+		}
+		r[arg.key] = arg
+	}
+	return r, nil
+}
+
+// formatArgs collects the arguments of a printf-style format string, where
+// arguments are either all numbered or all taken in sequence.
+type formatArgs struct {
+	args     []formatArg
+	next     int // number of the next argument taken in sequence
+	numbered bool
+	err      error
+}
+
+// add records an argument, numbered n or the next in sequence if n is 0.
+func (a *formatArgs) add(n int, typ, directive string) {
+	if n == 0 {
+		if a.numbered && a.err == nil {
+			a.err = errors.New("mixed numbered and unnumbered arguments")
+		}
+		a.next++
+		n = a.next
+	} else {
+		if a.next > 0 && a.err == nil {
+			a.err = errors.New("mixed numbered and unnumbered arguments")
+		}
+		a.numbered = true
+	}
+	a.args = append(a.args, formatArg{strconv.Itoa(n), typ, directive})
+}
+
+// cNumber parses the argument number at s[i:] if it is followed by '$', as
+// in "%2$d" or "%*2$d", and returns the index following it.
+func cNumber(s string, i int) (n, j int) {
+	j = i
+	for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+		j++
+	}
+	if j == i || j == len(s) || s[j] != '$' {
+		return 0, i
+	}
+	if n, _ = strconv.Atoi(s[i:j]); n == 0 {
+		return 0, i
+	}
+	return n, j + 1
+}
+
+// parseCFormat parses the directives of a C printf format string, including
+// the system-dependent directives of PO files such as "%<PRId64>".
+func parseCFormat(s string) ([]formatArg, error) {
+	var a formatArgs
+	for i := 0; i < len(s) && a.err == nil; i++ {
+		if s[i] != '%' {
+			continue
+		}
+		var start = i
+		i++
+		if i < len(s) && s[i] == '%' {
+			continue
+		}
+		var n int
+		n, i = cNumber(s, i)
+		for i < len(s) && strings.IndexByte("'-+ #0I", s[i]) != -1 {
+			i++
+		}
+		// width and precision
+		for prec := false; ; prec = true {
+			if i < len(s) && s[i] == '*' {
+				var w int
+				w, i = cNumber(s, i+1)
+				a.add(w, "int", "*")
+			}
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			if prec || i == len(s) || s[i] != '.' {
+				break
+			}
+			i++
+		}
+
+		var length string
+		for i < len(s) && strings.IndexByte("hlLqjzt", s[i]) != -1 {
+			length += s[i : i+1]
+			i++
+		}
+		if i < len(s) && s[i] == '<' {
+			// A system-dependent directive, e.g. "%<PRId64>".
+			var end = strings.IndexByte(s[i:], '>')
+			if end == -1 || !strings.HasPrefix(s[i:], "<PRI") || end < 6 {
+				return nil, fmt.Errorf("invalid directive %q", s[start:])
+			}
+			var macro = s[i+4 : i+end] // e.g. "d64" or "xPTR"
+			i += end
+			var class = cFormatClass(macro[0])
+			if class == "" || class == "float" || class == "char" {
+				return nil, fmt.Errorf("invalid directive %q", s[start:i+1])
+			}
+			a.add(n, macro[1:]+" "+class, s[start:i+1])
+			continue
+		}
+		if i == len(s) {
+			return nil, fmt.Errorf("unterminated directive %q", s[start:])
+		}
+		var class = cFormatClass(s[i])
+		if class == "" {
+			return nil, fmt.Errorf("invalid directive %q", s[start:i+1])
+		}
+		if length != "" {
+			class = length + " " + class
+		}
+		a.add(n, class, s[start:i+1])
+	}
+	return a.args, a.err
+}
+
+// cFormatClass returns the type of argument of a C conversion specifier.
+func cFormatClass(c byte) string {
+	switch c {
+	case 'd', 'i', 'o', 'u', 'x', 'X':
+		return "int"
+	case 'e', 'E', 'f', 'F', 'g', 'G', 'a', 'A':
+		return "float"
+	case 'c', 'C':
+		return "char"
+	case 's', 'S':
+		return "string"
+	case 'p':
+		return "pointer"
+	case 'n':
+		return "count"
+	}
+	return ""
+}
+
+// goIndex parses an explicit argument index at s[i:], as in "%[2]d", and
+// returns the index following it.
+func goIndex(s string, i int) (n, j int, err error) {
+	if i >= len(s) || s[i] != '[' {
+		return 0, i, nil
+	}
+	var end = strings.IndexByte(s[i:], ']')
+	if end == -1 {
+		return 0, i, errors.New("unterminated argument index")
+	}
+	n, err = strconv.Atoi(s[i+1 : i+end])
+	if err != nil || n < 1 {
+		return 0, i, fmt.Errorf("invalid argument index %q", s[i:i+end+1])
+	}
+	return n, i + end + 1, nil
+}
+
+// parseGoFormat parses the directives of a Go fmt format string. Explicit
+// argument indexes set the number of the following arguments, as in fmt.
+func parseGoFormat(s string) ([]formatArg, error) {
+	var (
+		args []formatArg
+		next = 1
+		i    int
+	)
+	// index consumes an explicit argument index, if any.
+	var index = func() error {
+		var n, j, err = goIndex(s, i)
+		if n != 0 {
+			next, i = n, j
+		}
+		return err
+	}
+	// number consumes a width or precision.
+	var number = func(start int) error {
+		if err := index(); err != nil {
+			return err
+		}
+		if i < len(s) && s[i] == '*' {
+			args = append(args, formatArg{strconv.Itoa(next), "int", s[start : i+1]})
+			next++
+			i++
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return nil
+	}
+
+	for ; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		var start = i
+		i++
+		for i < len(s) && strings.IndexByte("+-# 0", s[i]) != -1 {
+			i++
+		}
+		if err := number(start); err != nil {
+			return nil, err
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			if err := number(start); err != nil {
+				return nil, err
+			}
+		}
+		if err := index(); err != nil {
+			return nil, err
+		}
+		if i == len(s) {
+			return nil, fmt.Errorf("unterminated directive %q", s[start:])
+		}
+		if s[i] == '%' {
+			continue
+		}
+		var class = goFormatClass(s[i])
+		if class == "" {
+			return nil, fmt.Errorf("invalid directive %q", s[start:i+1])
+		}
+		args = append(args, formatArg{strconv.Itoa(next), class, s[start : i+1]})
+		next++
+	}
+	return args, nil
+}
+
+// goFormatClass returns the kind of argument of a Go verb.
+func goFormatClass(c byte) string {
+	switch c {
+	case 'v', 'T':
+		return "any"
+	case 't':
+		return "bool"
+	case 'b', 'c', 'd', 'o', 'O', 'U':
+		return "int"
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return "float"
+	case 's', 'q':
+		return "string"
+	case 'x', 'X':
+		return "hex"
+	case 'p':
+		return "pointer"
+	}
+	return ""
+}
+
+// parsePythonFormat parses the directives of a Python %-format string, whose
+// arguments are either all named, as in "%(count)d", or all positional.
+func parsePythonFormat(s string) ([]formatArg, error) {
+	var a formatArgs
+	var named, unnamed bool
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		var start = i
+		i++
+		var name string
+		if i < len(s) && s[i] == '(' {
+			var end = strings.IndexByte(s[i:], ')')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated directive %q", s[start:])
+			}
+			name = s[i+1 : i+end]
+			i += end + 1
+		}
+		for i < len(s) && strings.IndexByte("#0- +", s[i]) != -1 {
+			i++
+		}
+		for prec := false; ; prec = true {
+			if i < len(s) && s[i] == '*' {
+				if name != "" {
+					return nil, fmt.Errorf("invalid directive %q", s[start:i+1])
+				}
+				a.add(0, "int", "*")
+				unnamed = true
+				i++
+			}
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			if prec || i == len(s) || s[i] != '.' {
+				break
+			}
+			i++
+		}
+		for i < len(s) && strings.IndexByte("hlL", s[i]) != -1 {
+			i++
+		}
+		if i == len(s) {
+			return nil, fmt.Errorf("unterminated directive %q", s[start:])
+		}
+		if s[i] == '%' && i == start+1 {
+			continue
+		}
+		var class = pythonFormatClass(s[i])
+		if class == "" {
+			return nil, fmt.Errorf("invalid directive %q", s[start:i+1])
+		}
+		if name != "" {
+			named = true
+			a.args = append(a.args, formatArg{name, class, s[start : i+1]})
+		} else {
+			unnamed = true
+			a.add(0, class, s[start:i+1])
+		}
+		if named && unnamed {
+			return nil, errors.New("mixed named and unnamed arguments")
+		}
+	}
+	return a.args, nil
+}
+
+// pythonFormatClass returns the type of argument of a Python conversion.
+func pythonFormatClass(c byte) string {
+	switch c {
+	case 'd', 'i', 'o', 'u', 'x', 'X':
+		return "int"
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return "float"
+	case 'c':
+		return "char"
+	case 's', 'r', 'a':
+		return "string"
+	}
+	return ""
+}
+
+// parsePythonBraceFormat parses the replacement fields of a Python
+// str.format string, such as "{0}", "{name}" or "{}". Their format specs are
+// not checked.
+func parsePythonBraceFormat(s string) ([]formatArg, error) {
+	var (
+		args         []formatArg
+		next         int
+		auto, manual bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{") || strings.HasPrefix(s[i:], "}}"):
+			i++
+			continue
+		case s[i] == '}':
+			return nil, errors.New("single '}' encountered")
+		case s[i] != '{':
+			continue
+		}
+
+		// Find the end of the field, allowing nested fields in the spec.
+		var start, depth = i, 0
+		for ; i < len(s); i++ {
+			if s[i] == '{' {
+				depth++
+			} else if s[i] == '}' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if i == len(s) {
+			return nil, fmt.Errorf("unterminated field %q", s[start:])
+		}
+		var field = s[start : i+1]
+		var name = field[1 : len(field)-1]
+		if end := strings.IndexAny(name, ".[!:"); end != -1 {
+			name = name[:end]
+		}
+		if name == "" {
+			// Automatic numbering, from 0.
+			auto = true
+			name = strconv.Itoa(next)
+			next++
+		} else if _, err := strconv.Atoi(name); err == nil {
+			manual = true
+		}
+		if auto && manual {
+			return nil, errors.New("mixed automatic and manual field numbering")
+		}
+		args = append(args, formatArg{name, "", field})
+	}
+	return args, nil
+}
@@ -0,0 +1,68 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckFormat(t *testing.T) {
+	var tests = []struct {
+		flag, id, idPlural string
+		str                []string
+		expected           []string // fields with errors
+	}{
+		{"c-format", "%d files in %s", "", []string{"%d súborov v %s"}, nil},
+		{"c-format", "%d files in %s", "", []string{"v %2$s je %1$d súborov"}, nil},
+		{"c-format", "%d files in %s", "", []string{"%s súborov v %d"}, []string{"msgstr"}},
+		{"c-format", "%d files in %s", "", []string{"%d súborov"}, []string{"msgstr"}},
+		{"c-format", "%d files", "", []string{"%d súborov v %s"}, []string{"msgstr"}},
+		{"c-format", "%d files", "", []string{"%ld súborov"}, []string{"msgstr"}},
+		{"c-format", "%d files", "", []string{"%1$d súborov %s"}, []string{"msgstr"}},
+		{"c-format", "100%% of %*d", "", []string{"100%% z %*d"}, nil},
+		{"c-format", "%<PRIu64> bytes", "", []string{"%<PRIu64> bajtov"}, nil},
+		{"c-format", "%<PRIu64> bytes", "", []string{"%u bajtov"}, []string{"msgstr"}},
+		{"c-format", "%d files", "", []string{"%y súborov"}, []string{"msgstr"}},
+		{"c-format", "%d file", "%d files", []string{"jeden súbor", "%d súbory", "%s súborov", ""}, []string{"msgstr[2]"}},
+		{"c-format", "%d file", "%d files", []string{"%d súbor", "%d súbory %d"}, []string{"msgstr[1]"}},
+		{"c-format", "%z", "", []string{"%d"}, []string{"msgid"}},
+		{"no-c-format", "%z", "", []string{"%d"}, nil},
+		{"", "%d", "", []string{"%s"}, nil},
+
+		{"go-format", "%d files in %s", "", []string{"v %[2]s je %[1]d súborov"}, nil},
+		{"go-format", "%d files in %s", "", []string{"%[2]s %s"}, []string{"msgstr"}},
+		{"go-format", "%v of %6.2f%%", "", []string{"%v z %.1f %%"}, nil},
+		{"go-format", "%v items", "", []string{"%d položiek"}, []string{"msgstr"}},
+		{"go-format", "%*d", "", []string{"%[1]*[2]d"}, nil},
+		{"go-format", "%d", "", []string{"%[0]d"}, []string{"msgstr"}},
+
+		{"python-format", "%(count)d files in %(dir)s", "", []string{"v %(dir)s je %(count)d súborov"}, nil},
+		{"python-format", "%(count)d files", "", []string{"%(count)s súborov"}, []string{"msgstr"}},
+		{"python-format", "%d files in %s", "", []string{"%s súborov v %d"}, []string{"msgstr"}},
+		{"python-format", "%(count)d files", "", []string{"%(count)d súborov %s"}, []string{"msgstr"}},
+
+		{"python-brace-format", "{count} files in {dir!r:>10}", "", []string{"v {dir} je {count:d} súborov"}, nil},
+		{"python-brace-format", "{} files in {}", "", []string{"{1} obsahuje {0} súborov"}, nil},
+		{"python-brace-format", "{0} files", "", []string{"{} súborov {{}}"}, nil},
+		{"python-brace-format", "{count} files", "", []string{"{cnt} súborov"}, []string{"msgstr"}},
+		{"python-brace-format", "{count} files", "", []string{"{count súborov"}, []string{"msgstr"}},
+		{"python-brace-format", "{} files", "", []string{"{} súborov {0}"}, []string{"msgstr"}},
+	}
+	for _, test := range tests {
+		var msg = Message{
+			Comment:  Comment{Flags: []string{test.flag}},
+			Id:       test.id,
+			IdPlural: test.idPlural,
+			Str:      test.str,
+		}
+		var fields []string
+		for _, err := range msg.CheckFormat() {
+			fields = append(fields, err.Field)
+			if !strings.Contains(err.Error(), test.flag) {
+				t.Errorf("%s %q: unexpected error text %q", test.flag, test.str, err)
+			}
+		}
+		if strings.Join(fields, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("%s %q %q: expected errors in %v, got %v", test.flag, test.id, test.str, test.expected, msg.CheckFormat())
+		}
+	}
+}
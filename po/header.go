@@ -0,0 +1,148 @@
+package po
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+// HeaderDateFormat is the layout of the date fields in a PO header.
+const HeaderDateFormat = "2006-01-02 15:04-0700"
+
+// Header stores the fields of the header entry of a PO file.
+// Unlike textproto.MIMEHeader, the spelling and order of the keys are
+// preserved, so that files round-trip without spurious changes.
+type Header []HeaderField
+
+// HeaderField is a single "Key: Value" line of a Header.
+type HeaderField struct {
+	Key   string
+	Value string
+}
+
+// ParseHeader parses the msgstr of a header entry.
+// Lines without a colon continue the value of the previous field.
+func ParseHeader(str string) Header {
+	var h Header
+	for _, line := range strings.Split(str, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var i = strings.IndexByte(line, ':')
+		if i == -1 {
+			if len(h) > 0 {
+				h[len(h)-1].Value += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		h = append(h, HeaderField{
+			Key:   strings.TrimSpace(line[:i]),
+			Value: strings.TrimSpace(line[i+1:]),
+		})
+	}
+	return h
+}
+
+// Get returns the value of the first field with the given key, compared case
+// insensitively. It returns "" if there is no such field.
+func (h Header) Get(key string) string {
+	if i := h.index(key); i != -1 {
+		return h[i].Value
+	}
+	return ""
+}
+
+// Set sets the value of the field with the given key, keeping its position
+// and spelling. The field is appended if not present.
+func (h *Header) Set(key, value string) {
+	if i := h.index(key); i != -1 {
+		(*h)[i].Value = value
+		return
+	}
+	*h = append(*h, HeaderField{key, value})
+}
+
+// Del removes all fields with the given key.
+func (h *Header) Del(key string) {
+	var r = (*h)[:0]
+	for _, field := range *h {
+		if !strings.EqualFold(field.Key, key) {
+			r = append(r, field)
+		}
+	}
+	*h = r
+}
+
+func (h Header) index(key string) int {
+	for i, field := range h {
+		if strings.EqualFold(field.Key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ProjectIdVersion returns the Project-Id-Version field.
+func (h Header) ProjectIdVersion() string { return h.Get("Project-Id-Version") }
+
+// ReportMsgidBugsTo returns the Report-Msgid-Bugs-To field.
+func (h Header) ReportMsgidBugsTo() string { return h.Get("Report-Msgid-Bugs-To") }
+
+// LastTranslator returns the Last-Translator field.
+func (h Header) LastTranslator() string { return h.Get("Last-Translator") }
+
+// LanguageTeam returns the Language-Team field.
+func (h Header) LanguageTeam() string { return h.Get("Language-Team") }
+
+// Language returns the Language field.
+func (h Header) Language() string { return h.Get("Language") }
+
+// MIMEVersion returns the MIME-Version field.
+func (h Header) MIMEVersion() string { return h.Get("MIME-Version") }
+
+// ContentType returns the Content-Type field.
+func (h Header) ContentType() string { return h.Get("Content-Type") }
+
+// ContentTransferEncoding returns the Content-Transfer-Encoding field.
+func (h Header) ContentTransferEncoding() string { return h.Get("Content-Transfer-Encoding") }
+
+// PluralForms returns the Plural-Forms field.
+func (h Header) PluralForms() string { return h.Get("Plural-Forms") }
+
+// Charset returns the charset parameter of the Content-Type field, or "" if
+// it is missing or invalid.
+func (h Header) Charset() string {
+	var _, params, err = mime.ParseMediaType(h.ContentType())
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// POTCreationDate returns the parsed POT-Creation-Date field.
+func (h Header) POTCreationDate() (time.Time, error) {
+	return time.Parse(HeaderDateFormat, h.Get("POT-Creation-Date"))
+}
+
+// PORevisionDate returns the parsed PO-Revision-Date field.
+func (h Header) PORevisionDate() (time.Time, error) {
+	return time.Parse(HeaderDateFormat, h.Get("PO-Revision-Date"))
+}
+
+// String returns the header formatted as the msgstr of the header entry.
+func (h Header) String() string {
+	var buf bytes.Buffer
+	for _, field := range h {
+		buf.WriteString(field.Key + ": " + field.Value + "\n")
+	}
+	return buf.String()
+}
+
+// Write the header entry to a destination writer.
+func (h Header) WriteTo(w io.Writer) (n int64, err error) {
+	var wr = newBufferedWriter(w, WriteOptions{})
+	wr.header(h, Comment{})
+	return wr.result()
+}
@@ -0,0 +1,152 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var gnuHeaderPo = `
+msgid ""
+msgstr ""
+"Project-Id-Version: hello 1.0\n"
+"Report-Msgid-Bugs-To: bugs@example.com\n"
+"POT-Creation-Date: 2014-05-10 18:15+0200\n"
+"PO-Revision-Date: 2014-06-01 09:30+0000\n"
+"Last-Translator: Marcel Telka <marcel@telka.sk>\n"
+"Language-Team: Slovak <sk-i18n@lists.linux.sk>\n"
+"Language: sk\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+`[1:]
+
+func TestHeaderRoundTrip(t *testing.T) {
+	var f, err = Parse(strings.NewReader(gnuHeaderPo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != gnuHeaderPo {
+		t.Errorf("expected:\n%v\ngot:\n%v", gnuHeaderPo, buf.String())
+	}
+}
+
+// xgettextPot is the start of a template written by xgettext.
+var xgettextPot = `
+# SOME DESCRIPTIVE TITLE.
+# Copyright (C) YEAR THE PACKAGE'S COPYRIGHT HOLDER
+# This file is distributed under the same license as the PACKAGE package.
+# FIRST AUTHOR <EMAIL@ADDRESS>, YEAR.
+#
+#, fuzzy
+msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"Report-Msgid-Bugs-To: \n"
+"POT-Creation-Date: 2014-05-10 18:15+0200\n"
+"PO-Revision-Date: YEAR-MO-DA HO:MI+ZONE\n"
+"Last-Translator: FULL NAME <EMAIL@ADDRESS>\n"
+"Language-Team: LANGUAGE <LL@li.org>\n"
+"Language: \n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+
+#: hello.c:10
+#, c-format
+msgid "Hello, %s!\n"
+msgstr ""
+
+`[1:]
+
+func TestHeaderCommentRoundTrip(t *testing.T) {
+	var f, err = Parse(strings.NewReader(xgettextPot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = Comment{
+		TranslatorComments: []string{
+			"SOME DESCRIPTIVE TITLE.",
+			"Copyright (C) YEAR THE PACKAGE'S COPYRIGHT HOLDER",
+			"This file is distributed under the same license as the PACKAGE package.",
+			"FIRST AUTHOR <EMAIL@ADDRESS>, YEAR.",
+			"",
+		},
+		Flags: []string{"fuzzy"},
+	}
+	if !reflect.DeepEqual(expected, f.HeaderComment) {
+		t.Errorf("expected %#v, got %#v", expected, f.HeaderComment)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != xgettextPot {
+		t.Errorf("expected:\n%v\ngot:\n%v", xgettextPot, buf.String())
+	}
+
+	var dec = NewDecoder(strings.NewReader(xgettextPot))
+	if c, err := dec.HeaderComment(); err != nil || !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected %#v, got %#v, %v", expected, c, err)
+	}
+}
+
+func TestHeaderAccessors(t *testing.T) {
+	var f, err = Parse(strings.NewReader(gnuHeaderPo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h = f.Header
+	var tests = []struct{ actual, expected string }{
+		{h.ProjectIdVersion(), "hello 1.0"},
+		{h.ReportMsgidBugsTo(), "bugs@example.com"},
+		{h.LastTranslator(), "Marcel Telka <marcel@telka.sk>"},
+		{h.LanguageTeam(), "Slovak <sk-i18n@lists.linux.sk>"},
+		{h.Language(), "sk"},
+		{h.MIMEVersion(), "1.0"},
+		{h.ContentType(), "text/plain; charset=UTF-8"},
+		{h.Charset(), "UTF-8"},
+		{h.ContentTransferEncoding(), "8bit"},
+		{h.PluralForms(), "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;"},
+		{h.Get("mime-version"), "1.0"},
+		{h.Get("X-Missing"), ""},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+
+	created, err := h.POTCreationDate()
+	if err != nil || !created.Equal(time.Date(2014, 5, 10, 16, 15, 0, 0, time.UTC)) {
+		t.Errorf("POTCreationDate: got %v, %v", created, err)
+	}
+	revised, err := h.PORevisionDate()
+	if err != nil || !revised.Equal(time.Date(2014, 6, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("PORevisionDate: got %v, %v", revised, err)
+	}
+}
+
+func TestHeaderSetDel(t *testing.T) {
+	var h = ParseHeader("Language: sk\nMIME-Version: 1.0\n")
+	h.Set("mime-version", "2.0")
+	h.Set("X-Generator", "test")
+	var expected = Header{{"Language", "sk"}, {"MIME-Version", "2.0"}, {"X-Generator", "test"}}
+	if !reflect.DeepEqual(expected, h) {
+		t.Errorf("expected %v, got %v", expected, h)
+	}
+	h.Del("LANGUAGE")
+	expected = Header{{"MIME-Version", "2.0"}, {"X-Generator", "test"}}
+	if !reflect.DeepEqual(expected, h) {
+		t.Errorf("expected %v, got %v", expected, h)
+	}
+}
@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/robfig/gettext/po"
+)
+
+// RequiredHeaderFields are the header fields reported missing by the
+// "header" check. Plural-Forms is also required if the file has plural
+// messages.
+var RequiredHeaderFields = []string{
+	"Project-Id-Version",
+	"Language",
+	"MIME-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+}
+
+// AcceleratorMarkers are the characters that may mark keyboard accelerators,
+// as in "&File" or "_File".
+var AcceleratorMarkers = "&_"
+
+func init() {
+	Register(MessageCheck{"plural-count", checkPluralCount})
+	Register(MessageCheck{"whitespace", checkWhitespace})
+	Register(MessageCheck{"untranslated", checkUntranslated})
+	Register(MessageCheck{"accelerator", checkAccelerator})
+	Register(MessageCheck{"format", checkFormat})
+	Register(duplicateCheck{})
+	Register(headerCheck{})
+}
+
+// checkPluralCount checks that plural messages have as many msgstr as the
+// plural rule of the file calls for.
+func checkPluralCount(f po.File, msg po.Message) []Issue {
+	if msg.IdPlural == "" {
+		return nil
+	}
+	var rule = f.PluralRule
+	if rule == nil {
+		var err error
+		if rule, err = po.ParsePluralRule(f.Header.PluralForms()); err != nil {
+			// Reported by the header check.
+			return nil
+		}
+	}
+	if len(msg.Str) != rule.NPlurals {
+		return []Issue{NewIssue(msg, "", fmt.Sprintf("%d plural forms, expected %d", len(msg.Str), rule.NPlurals))}
+	}
+	return nil
+}
+
+// checkWhitespace checks that translations begin and end with the same
+// whitespace as the msgid, and in particular with the same newlines.
+func checkWhitespace(f po.File, msg po.Message) []Issue {
+	var r []Issue
+	forEachStr(msg, func(field, id, str string) {
+		switch {
+		case strings.HasPrefix(id, "\n") != strings.HasPrefix(str, "\n"):
+			r = append(r, NewIssue(msg, field, "leading newline mismatch"))
+		case strings.HasSuffix(id, "\n") != strings.HasSuffix(str, "\n"):
+			r = append(r, NewIssue(msg, field, "trailing newline mismatch"))
+		case startsWithSpace(id) != startsWithSpace(str):
+			r = append(r, NewIssue(msg, field, "leading whitespace mismatch"))
+		case endsWithSpace(id) != endsWithSpace(str):
+			r = append(r, NewIssue(msg, field, "trailing whitespace mismatch"))
+		}
+	})
+	return r
+}
+
+func startsWithSpace(s string) bool {
+	var r, _ = utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	var r, _ = utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+// checkUntranslated reports messages with an empty msgstr that are not
+// flagged fuzzy.
+func checkUntranslated(f po.File, msg po.Message) []Issue {
+	if msg.HasFlag("fuzzy") {
+		return nil
+	}
+	for _, str := range msg.Str {
+		if str == "" {
+			return []Issue{NewIssue(msg, "", "untranslated")}
+		}
+	}
+	if len(msg.Str) == 0 {
+		return []Issue{NewIssue(msg, "", "untranslated")}
+	}
+	return nil
+}
+
+// checkAccelerator checks that translations of a msgid with a keyboard
+// accelerator have exactly one accelerator too, like msgfmt
+// --check-accelerators.
+func checkAccelerator(f po.File, msg po.Message) []Issue {
+	var r []Issue
+	for _, marker := range AcceleratorMarkers {
+		if accelerators(msg.Id, marker) != 1 {
+			continue
+		}
+		forEachStr(msg, func(field, id, str string) {
+			switch n := accelerators(str, marker); n {
+			case 0:
+				r = append(r, NewIssue(msg, field, fmt.Sprintf("missing accelerator %q", marker)))
+			case 1:
+			default:
+				r = append(r, NewIssue(msg, field, fmt.Sprintf("%d accelerators %q, expected 1", n, marker)))
+			}
+		})
+	}
+	return r
+}
+
+// accelerators returns the number of marker characters in s that are
+// followed by a letter or digit. Doubled markers, such as "&&", are literal.
+func accelerators(s string, marker rune) int {
+	var n int
+	for s != "" {
+		var i = strings.IndexRune(s, marker)
+		if i == -1 {
+			break
+		}
+		s = s[i+utf8.RuneLen(marker):]
+		var next, size = utf8.DecodeRuneInString(s)
+		switch {
+		case next == marker:
+			s = s[size:]
+		case unicode.IsLetter(next) || unicode.IsDigit(next):
+			n++
+		}
+	}
+	return n
+}
+
+// checkFormat reports translations whose format directives do not match
+// those of the msgid, as Message.CheckFormat does.
+func checkFormat(f po.File, msg po.Message) []Issue {
+	var r []Issue
+	for _, err := range msg.CheckFormat() {
+		r = append(r, NewIssue(msg, err.Field, err.Format+": "+err.Err.Error()))
+	}
+	return r
+}
+
+// forEachStr calls fn for each non-empty msgstr of the message, with the
+// msgid it translates: msgid for msgstr[0], and msgid_plural for the others.
+func forEachStr(msg po.Message, fn func(field, id, str string)) {
+	for i, str := range msg.Str {
+		if str == "" {
+			continue
+		}
+		if msg.IdPlural == "" {
+			fn("msgstr", msg.Id, str)
+		} else if i == 0 {
+			fn("msgstr[0]", msg.Id, str)
+		} else {
+			fn("msgstr["+strconv.Itoa(i)+"]", msg.IdPlural, str)
+		}
+	}
+}
+
+// duplicateCheck reports messages with the same msgctxt and msgid as an
+// earlier one.
+type duplicateCheck struct{}
+
+func (duplicateCheck) Name() string { return "duplicate" }
+
+func (duplicateCheck) Check(f po.File) []Issue {
+	var r []Issue
+	var seen = make(map[[2]string]bool)
+	for _, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		var key = [2]string{msg.Ctxt, msg.Id}
+		if seen[key] {
+			r = append(r, NewIssue(msg, "", "duplicate message"))
+		}
+		seen[key] = true
+	}
+	return r
+}
+
+// headerCheck reports missing or invalid header fields.
+type headerCheck struct{}
+
+func (headerCheck) Name() string { return "header" }
+
+func (headerCheck) Check(f po.File) []Issue {
+	var r []Issue
+	for _, key := range RequiredHeaderFields {
+		if f.Header.Get(key) == "" {
+			r = append(r, Issue{Text: "missing header field " + key})
+		}
+	}
+	if ct := f.Header.ContentType(); ct != "" && f.Header.Charset() == "" {
+		r = append(r, Issue{Text: "missing charset in Content-Type"})
+	}
+
+	var plural bool
+	for _, msg := range f.Messages {
+		plural = plural || msg.IdPlural != "" && !msg.Obsolete
+	}
+	switch pluralForms := f.Header.PluralForms(); {
+	case pluralForms == "" && plural:
+		r = append(r, Issue{Text: "missing header field Plural-Forms"})
+	case pluralForms != "" && !po.IsTemplatePluralForms(pluralForms):
+		if _, _, err := po.ParsePluralForms(pluralForms); err != nil {
+			r = append(r, Issue{Text: "invalid Plural-Forms: " + err.Error()})
+		}
+	}
+	return r
+}
@@ -0,0 +1,152 @@
+// Package lint checks PO files for common translation mistakes, such as
+// missing plural forms or broken format directives.
+//
+// Checks are registered by name, so that programs can select them, and new
+// ones can be added with Register.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/robfig/gettext/po"
+)
+
+// Issue is a problem found by a check.
+type Issue struct {
+	Check      string   `json:"check"`                // name of the check that found it
+	Ctxt       string   `json:"msgctxt,omitempty"`    // msgctxt of the message
+	Id         string   `json:"msgid"`                // msgid of the message, "" for the header
+	References []string `json:"references,omitempty"` // source references of the message
+	Field      string   `json:"field,omitempty"`      // field with the problem, such as "msgstr[1]"
+	Text       string   `json:"text"`                 // description of the problem
+}
+
+func (i Issue) String() string {
+	var s = i.Check + ": "
+	switch {
+	case i.Ctxt != "":
+		s += fmt.Sprintf("msgctxt %q msgid %q", i.Ctxt, i.Id)
+	case i.Id != "":
+		s += fmt.Sprintf("msgid %q", i.Id)
+	default:
+		s += "header"
+	}
+	if i.Field != "" {
+		s += ": " + i.Field
+	}
+	return s + ": " + i.Text
+}
+
+// Check is a check run over a File.
+type Check interface {
+	// Name returns the name the check is registered under, such as
+	// "untranslated".
+	Name() string
+
+	// Check returns the issues found in the file. The Check field of the
+	// issues is filled in by Run.
+	Check(f po.File) []Issue
+}
+
+var (
+	mu     sync.RWMutex
+	checks = make(map[string]Check)
+)
+
+// Register makes a check available by its name. It panics if a check with the
+// same name is already registered.
+func Register(c Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := checks[c.Name()]; dup {
+		panic("lint: Register called twice for check " + c.Name())
+	}
+	checks[c.Name()] = c
+}
+
+// Lookup returns the check registered under the given name.
+func Lookup(name string) (Check, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	var c, ok = checks[name]
+	return c, ok
+}
+
+// Checks returns the registered checks, sorted by name.
+func Checks() []Check {
+	mu.RLock()
+	defer mu.RUnlock()
+	var r []Check
+	for _, c := range checks {
+		r = append(r, c)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Name() < r[j].Name() })
+	return r
+}
+
+// Select returns the registered checks with the given names, or all of them
+// if no name is given. Names not registered are an error.
+func Select(names ...string) ([]Check, error) {
+	if len(names) == 0 {
+		return Checks(), nil
+	}
+	var r []Check
+	for _, name := range names {
+		var c, ok = Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("lint: unknown check %q", name)
+		}
+		r = append(r, c)
+	}
+	return r, nil
+}
+
+// Run runs the checks over the file and returns the issues they found, in
+// the order of the checks.
+func Run(f po.File, checks []Check) []Issue {
+	var r []Issue
+	for _, c := range checks {
+		for _, issue := range c.Check(f) {
+			issue.Check = c.Name()
+			r = append(r, issue)
+		}
+	}
+	return r
+}
+
+// MessageCheck is a Check that looks at each message of a file in turn,
+// skipping the header and obsolete messages.
+type MessageCheck struct {
+	CheckName string
+	Func      func(f po.File, msg po.Message) []Issue
+}
+
+// Name returns the CheckName of the check.
+func (c MessageCheck) Name() string {
+	return c.CheckName
+}
+
+// Check calls Func for each message of the file.
+func (c MessageCheck) Check(f po.File) []Issue {
+	var r []Issue
+	for _, msg := range f.Messages {
+		if msg.Obsolete || msg.Id == "" {
+			continue
+		}
+		r = append(r, c.Func(f, msg)...)
+	}
+	return r
+}
+
+// NewIssue returns an issue about a message.
+func NewIssue(msg po.Message, field, text string) Issue {
+	return Issue{
+		Ctxt:       msg.Ctxt,
+		Id:         msg.Id,
+		References: msg.References,
+		Field:      field,
+		Text:       text,
+	}
+}
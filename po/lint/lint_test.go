@@ -0,0 +1,149 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robfig/gettext/po"
+)
+
+const src = `msgid ""
+msgstr ""
+"Project-Id-Version: test\n"
+"Language: sk\n"
+"Content-Type: text/plain\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+#: main.go:1
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+
+#, c-format
+msgid "%d of %s"
+msgstr "%s z %d"
+
+msgid "Line\n"
+msgstr "Riadok"
+
+msgid " Padded"
+msgstr "Odsadené"
+
+msgid "Untranslated"
+msgstr ""
+
+#, fuzzy
+msgid "Fuzzy"
+msgstr ""
+
+msgid "&Open"
+msgstr "Otvoriť"
+
+msgid "_Save && Quit"
+msgstr "_Uložiť a _skončiť"
+
+msgid "Tom && Jerry"
+msgstr "Tom a Jerry"
+
+msgid "Line\n"
+msgstr "Riadok\n"
+
+#~ msgid "Untranslated"
+#~ msgstr ""
+`
+
+func TestRun(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var checks []Check
+	if checks, err = Select(); err != nil {
+		t.Fatal(err)
+	}
+	var issues = Run(f, checks)
+
+	var expected = []Issue{
+		{Check: "accelerator", Id: "&Open", Field: "msgstr", Text: `missing accelerator '&'`},
+		{Check: "accelerator", Id: "_Save && Quit", Field: "msgstr", Text: `2 accelerators '_', expected 1`},
+		{Check: "duplicate", Id: "Line\n", Text: "duplicate message"},
+		{Check: "format", Id: "%d of %s", Field: "msgstr", Text: `c-format: argument 1 is "%s", unlike "%d" of msgid`},
+		{Check: "header", Text: "missing header field MIME-Version"},
+		{Check: "header", Text: "missing header field Content-Transfer-Encoding"},
+		{Check: "header", Text: "missing charset in Content-Type"},
+		{Check: "plural-count", Id: "%d file", References: []string{"main.go:1"}, Text: "2 plural forms, expected 3"},
+		{Check: "untranslated", Id: "Untranslated", Text: "untranslated"},
+		{Check: "whitespace", Id: "Line\n", Field: "msgstr", Text: "trailing newline mismatch"},
+		{Check: "whitespace", Id: " Padded", Field: "msgstr", Text: "leading whitespace mismatch"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, issues)
+	}
+}
+
+func TestHeaderPluralForms(t *testing.T) {
+	var checks, err = Select("header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		pluralForms string
+		invalid     bool
+	}{
+		{po.TemplatePluralForms, false},
+		{"nplurals=INTEGER;plural=EXPRESSION;", false},
+		{"nplurals=2; plural=(n != 1);", false},
+		{"nplurals=INTEGER; plural=(n != 1);", true},
+	}
+	for _, test := range tests {
+		var f po.File
+		f.Header.Set("Plural-Forms", test.pluralForms)
+		var invalid bool
+		for _, issue := range Run(f, checks) {
+			invalid = invalid || strings.HasPrefix(issue.Text, "invalid Plural-Forms")
+		}
+		if invalid != test.invalid {
+			t.Errorf("%q: expected invalid %v, got %v", test.pluralForms, test.invalid, invalid)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var checks, err = Select("untranslated", "duplicate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].Name() != "untranslated" || checks[1].Name() != "duplicate" {
+		t.Errorf("unexpected checks %v", checks)
+	}
+	if _, err = Select("nonexistent"); err == nil {
+		t.Error("expected an error for an unknown check")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(MessageCheck{"test-long", func(f po.File, msg po.Message) []Issue {
+		if len(msg.Id) > 10 {
+			return []Issue{NewIssue(msg, "msgid", "too long")}
+		}
+		return nil
+	}})
+	var f = po.File{Messages: []po.Message{{Id: "short"}, {Id: "rather long"}}}
+	var c, ok = Lookup("test-long")
+	if !ok {
+		t.Fatal("check not registered")
+	}
+	var issues = Run(f, []Check{c})
+	if len(issues) != 1 || issues[0].Check != "test-long" || issues[0].Id != "rather long" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic registering a duplicate check")
+		}
+	}()
+	Register(MessageCheck{"test-long", nil})
+}
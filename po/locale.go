@@ -0,0 +1,150 @@
+package po
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale identifies a language and its variety, as given by a POSIX locale
+// name such as "de_DE.UTF-8" or "sr_RS@latin", or a BCP 47 language tag such
+// as "zh-Hant-TW" or "ca-ES-valencia".
+type Locale struct {
+	Language  string // ISO 639 code in lowercase, e.g. "zh"
+	Script    string // ISO 15924 code in title case, e.g. "Hant"
+	Territory string // ISO 3166 code in uppercase or UN M.49 number, e.g. "TW"
+	Codeset   string // character set of a POSIX locale, e.g. "UTF-8"
+	Modifier  string // POSIX modifier or BCP 47 variant, e.g. "latin" or "valencia"
+}
+
+// languageAliases maps deprecated language codes to their replacement.
+var languageAliases = map[string]string{
+	"iw": "he",
+	"in": "id",
+	"ji": "yi",
+	"jw": "jv",
+	"mo": "ro",
+}
+
+// ParseLocale parses a POSIX locale name, language[_territory][.codeset][@modifier],
+// or a BCP 47 language tag, language[-script][-region][-variant]. The parts
+// may be separated by either '_' or '-', and are canonicalized: for example
+// "zh_hant-tw" is the same as "zh-Hant-TW", and "iw" is "he". BCP 47
+// extensions and private use subtags are ignored.
+//
+// The "C" and "POSIX" locales, which stand for no translation, are the zero
+// Locale.
+func ParseLocale(s string) (Locale, error) {
+	var l Locale
+	if s == "C" || s == "POSIX" || strings.HasPrefix(s, "C.") {
+		return l, nil
+	}
+	var name = s
+	if i := strings.IndexByte(name, '@'); i != -1 {
+		name, l.Modifier = name[:i], name[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i != -1 {
+		name, l.Codeset = name[:i], name[i+1:]
+	}
+
+	var tags = strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	if len(tags) == 0 || !isAlpha(tags[0]) || len(tags[0]) < 2 || len(tags[0]) > 3 {
+		return Locale{}, fmt.Errorf("po: invalid locale %q", s)
+	}
+	l.Language = strings.ToLower(tags[0])
+	if alias, ok := languageAliases[l.Language]; ok {
+		l.Language = alias
+	}
+
+	for _, tag := range tags[1:] {
+		switch {
+		case len(tag) == 1:
+			// An extension or private use: the rest is ignored.
+			return l, nil
+		case len(tag) == 3 && isAlpha(tag) && l.Script == "" && l.Territory == "":
+			// An extended language subtag, e.g. "yue" in "zh-yue".
+			l.Language = strings.ToLower(tag)
+		case len(tag) == 4 && isAlpha(tag) && l.Script == "" && l.Territory == "":
+			l.Script = strings.ToUpper(tag[:1]) + strings.ToLower(tag[1:])
+		case (len(tag) == 2 && isAlpha(tag) || len(tag) == 3 && isDigits(tag)) && l.Territory == "":
+			l.Territory = strings.ToUpper(tag)
+		case len(tag) >= 5 || len(tag) == 4 && tag[0] >= '0' && tag[0] <= '9':
+			if l.Modifier == "" {
+				l.Modifier = strings.ToLower(tag)
+			}
+		default:
+			return Locale{}, fmt.Errorf("po: invalid locale %q", s)
+		}
+	}
+	return l, nil
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the locale in the form used to name catalogs:
+// language[_Script][_TERRITORY][.codeset][@modifier].
+func (l Locale) String() string {
+	return l.format(true, true, true, true)
+}
+
+func (l Locale) format(script, territory, codeset, modifier bool) string {
+	var s = l.Language
+	if script && l.Script != "" {
+		s += "_" + l.Script
+	}
+	if territory && l.Territory != "" {
+		s += "_" + l.Territory
+	}
+	if codeset && l.Codeset != "" {
+		s += "." + l.Codeset
+	}
+	if modifier && l.Modifier != "" {
+		s += "@" + l.Modifier
+	}
+	return s
+}
+
+// Fallbacks returns the names to look for catalogs of the locale under, from
+// the most to the least specific, as GNU gettext does: the script matters
+// most, then the modifier, the territory and the codeset. For example
+// "de_DE.UTF-8@euro" gives "de_DE.UTF-8@euro", "de_DE@euro", "de.UTF-8@euro",
+// "de@euro", "de_DE.UTF-8", "de_DE", "de.UTF-8" and "de", and "zh-Hant-TW"
+// gives "zh_Hant_TW", "zh_Hant", "zh_TW" and "zh".
+func (l Locale) Fallbacks() []string {
+	if l.Language == "" {
+		return nil
+	}
+	var (
+		r    []string
+		seen = make(map[string]bool)
+	)
+	for mask := 15; mask >= 0; mask-- {
+		var script, modifier, territory, codeset = mask&8 != 0, mask&4 != 0, mask&2 != 0, mask&1 != 0
+		if script && l.Script == "" || modifier && l.Modifier == "" ||
+			territory && l.Territory == "" || codeset && l.Codeset == "" {
+			continue
+		}
+		var name = l.format(script, territory, codeset, modifier)
+		if !seen[name] {
+			seen[name] = true
+			r = append(r, name)
+		}
+	}
+	return r
+}
+
+// localeFallbacks returns the fallbacks of the given locale, or the locale
+// itself if it can not be parsed.
+func localeFallbacks(lang string) []string {
+	var l, err = ParseLocale(lang)
+	if err != nil {
+		return []string{lang}
+	}
+	return l.Fallbacks()
+}
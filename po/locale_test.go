@@ -0,0 +1,76 @@
+package po
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	var tests = []struct {
+		input    string
+		expected Locale
+		str      string
+	}{
+		{"de", Locale{Language: "de"}, "de"},
+		{"de_DE.UTF-8", Locale{Language: "de", Territory: "DE", Codeset: "UTF-8"}, "de_DE.UTF-8"},
+		{"de_DE.UTF-8@euro", Locale{Language: "de", Territory: "DE", Codeset: "UTF-8", Modifier: "euro"}, "de_DE.UTF-8@euro"},
+		{"sr@latin", Locale{Language: "sr", Modifier: "latin"}, "sr@latin"},
+		{"zh-Hant-TW", Locale{Language: "zh", Script: "Hant", Territory: "TW"}, "zh_Hant_TW"},
+		{"ZH_hant_tw", Locale{Language: "zh", Script: "Hant", Territory: "TW"}, "zh_Hant_TW"},
+		{"en-GB", Locale{Language: "en", Territory: "GB"}, "en_GB"},
+		{"es-419", Locale{Language: "es", Territory: "419"}, "es_419"},
+		{"ca-ES-valencia", Locale{Language: "ca", Territory: "ES", Modifier: "valencia"}, "ca_ES@valencia"},
+		{"iw_IL", Locale{Language: "he", Territory: "IL"}, "he_IL"},
+		{"fil", Locale{Language: "fil"}, "fil"},
+		{"en-US-u-ca-gregory", Locale{Language: "en", Territory: "US"}, "en_US"},
+		{"C", Locale{}, ""},
+		{"POSIX", Locale{}, ""},
+		{"C.UTF-8", Locale{}, ""},
+	}
+	for _, test := range tests {
+		var actual, err = ParseLocale(test.input)
+		if err != nil {
+			t.Errorf("%v: %v", test.input, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("%v: expected %+v, got %+v", test.input, test.expected, actual)
+		}
+		if actual.String() != test.str {
+			t.Errorf("%v: expected %q, got %q", test.input, test.str, actual.String())
+		}
+	}
+
+	for _, input := range []string{"", "e", "1234", "template", "en_GB_US", "en_12"} {
+		if _, err := ParseLocale(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	var tests = []struct {
+		input    string
+		expected []string
+	}{
+		{"de", []string{"de"}},
+		{"pt-BR", []string{"pt_BR", "pt"}},
+		{"sr_RS@latin", []string{"sr_RS@latin", "sr@latin", "sr_RS", "sr"}},
+		{"de_DE.UTF-8@euro", []string{
+			"de_DE.UTF-8@euro", "de_DE@euro", "de.UTF-8@euro", "de@euro",
+			"de_DE.UTF-8", "de_DE", "de.UTF-8", "de",
+		}},
+		{"zh-Hant-TW", []string{"zh_Hant_TW", "zh_Hant", "zh_TW", "zh"}},
+		{"C", nil},
+	}
+	for _, test := range tests {
+		var l, err = ParseLocale(test.input)
+		if err != nil {
+			t.Errorf("%v: %v", test.input, err)
+			continue
+		}
+		if actual := l.Fallbacks(); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%v: expected %q, got %q", test.input, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package po
+
+import (
+	"math"
+	"sort"
+)
+
+// contextPenalty scales the score of matches found in a different context.
+const contextPenalty = 0.9
+
+// maxVerified bounds the number of candidates whose similarity is computed
+// exactly for each query.
+const maxVerified = 200
+
+// maxCandidates bounds the number of messages considered for each query. They
+// are taken from the messages containing the rarest trigrams of the query
+// first, so that trigrams common to most messages do not make every query
+// scan the whole index.
+const maxCandidates = 1000
+
+// Matcher finds the messages whose msgid is most similar to a given one, as
+// needed for fuzzy matching when merging or for translation memory
+// suggestions. Messages are indexed by the trigrams of their msgid, so that
+// only messages sharing some of them are compared, which keeps lookups fast on
+// large catalogs. As a result matching is approximate: messages sharing no
+// trigram with the query are never found, and when many messages share its
+// trigrams, only those sharing its rarest ones are compared.
+//
+// A Matcher is safe for concurrent use.
+type Matcher struct {
+	msgs  []Message
+	index map[uint64][]int32 // indexes of the messages containing each trigram
+	sizes []int32            // number of distinct trigrams of each message
+}
+
+// Match is a message found by a Matcher.
+type Match struct {
+	Message Message
+	Index   int     // index of the message in the slice given to NewMatcher
+	Score   float64 // similarity to the query, between 0 and 1
+}
+
+// NewMatcher returns a Matcher for the given messages.
+func NewMatcher(msgs []Message) *Matcher {
+	var m = &Matcher{
+		msgs:  msgs,
+		index: make(map[uint64][]int32),
+		sizes: make([]int32, len(msgs)),
+	}
+	for i, msg := range msgs {
+		var grams = trigrams(msg.Id)
+		m.sizes[i] = int32(len(grams))
+		for _, gram := range grams {
+			m.index[gram] = append(m.index[gram], int32(i))
+		}
+	}
+	return m
+}
+
+// Match returns up to limit messages whose msgid is at least threshold
+// similar to id, best first. The similarity is that of the fstrcmp function of
+// GNU gettext; messages in a context other than ctxt score slightly lower.
+// There is no limit if limit is zero.
+func (m *Matcher) Match(ctxt, id string, threshold float64, limit int) []Match {
+	var grams = trigrams(id)
+	sort.Slice(grams, func(a, b int) bool {
+		return len(m.index[grams[a]]) < len(m.index[grams[b]])
+	})
+
+	// A message passing the Dice prefilter below shares at least minShared
+	// trigrams with the query, so it contains one of the len(grams)-minShared+1
+	// rarest ones: only their messages are candidates.
+	var minDice = threshold / 3
+	var minShared = int(math.Ceil(minDice * float64(len(grams)) / (2 - minDice)))
+	if minShared < 1 {
+		minShared = 1
+	}
+	var seen = make(map[int32]bool)
+	var ids []int32
+gather:
+	for _, gram := range grams[:max(len(grams)-minShared+1, 0)] {
+		var postings = m.index[gram]
+		if len(ids) > 0 && len(ids)+len(postings) > maxCandidates {
+			break
+		}
+		for _, i := range postings {
+			if len(ids) == maxCandidates {
+				break gather
+			}
+			if !seen[i] {
+				seen[i] = true
+				ids = append(ids, i)
+			}
+		}
+	}
+
+	// Count the trigrams each candidate shares with the query, intersecting
+	// the sorted candidates with the sorted posting list of each trigram.
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	var shared = make([]int32, len(ids))
+	for _, gram := range grams {
+		var postings = m.index[gram]
+		var k int
+		for c, i := range ids {
+			k = gallop(postings, k, i)
+			if k == len(postings) {
+				break
+			}
+			if postings[k] == i {
+				shared[c]++
+			}
+		}
+	}
+
+	// Rank the candidates by the Dice coefficient of their trigrams, and
+	// compute the exact similarity of the most promising ones.
+	type candidate struct {
+		i    int32
+		dice float64
+	}
+	var candidates []candidate
+	for c, i := range ids {
+		var n = shared[c]
+		var dice = float64(2*n) / float64(int32(len(grams))+m.sizes[i])
+		if dice >= threshold/3 {
+			candidates = append(candidates, candidate{i, dice})
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].dice != candidates[b].dice {
+			return candidates[a].dice > candidates[b].dice
+		}
+		return candidates[a].i < candidates[b].i
+	})
+	if len(candidates) > maxVerified {
+		candidates = candidates[:maxVerified]
+	}
+
+	var matches []Match
+	for _, c := range candidates {
+		var msg = m.msgs[c.i]
+		var score = similarity(msg.Id, id, threshold)
+		if msg.Ctxt != ctxt {
+			score *= contextPenalty
+		}
+		if score >= threshold {
+			matches = append(matches, Match{msg, int(c.i), score})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Index < matches[b].Index
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// gallop returns the index of the first element of sorted that is at least x,
+// searching from index k onwards with steps of increasing size, which is
+// faster than a binary search when it is near k.
+func gallop(sorted []int32, k int, x int32) int {
+	var step = 1
+	for k+step < len(sorted) && sorted[k+step] < x {
+		k += step
+		step *= 2
+	}
+	var end = min(k+step+1, len(sorted))
+	return k + sort.Search(end-k, func(j int) bool { return sorted[k+j] >= x })
+}
+
+// trigrams returns the distinct trigrams of runes in s, padded so that the
+// start and end of s form trigrams of their own.
+func trigrams(s string) []uint64 {
+	if s == "" {
+		return nil
+	}
+	var runes = append(append([]rune{0}, []rune(s)...), 0)
+	var seen = make(map[uint64]bool, len(runes))
+	var grams []uint64
+	for i := 0; i+3 <= len(runes); i++ {
+		var gram = uint64(runes[i])<<42 | uint64(runes[i+1])<<21 | uint64(runes[i+2])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// similarity returns how similar the two strings are, between 0 and 1:
+// twice the length of their longest common subsequence of runes divided by
+// their total length, as computed by the fstrcmp function of GNU gettext.
+// It returns 0 early if the result cannot reach min.
+func similarity(a, b string, min float64) float64 {
+	var ra, rb = []rune(a), []rune(b)
+	var total = len(ra) + len(rb)
+	if total == 0 {
+		return 1
+	}
+	var shorter = len(ra)
+	if len(rb) < shorter {
+		shorter = len(rb)
+	}
+	if float64(2*shorter)/float64(total) < min {
+		return 0
+	}
+
+	var prev, cur = make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for i := range ra {
+		for j := range rb {
+			switch {
+			case ra[i] == rb[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return float64(2*prev[len(rb)]) / float64(total)
+}
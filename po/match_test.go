@@ -0,0 +1,76 @@
+package po
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	var m = NewMatcher([]Message{
+		{Id: "Save the file"},
+		{Id: "Save the files"},
+		{Id: "Open", Ctxt: "menu"},
+		{Id: "Open"},
+		{Id: "Something else entirely"},
+		{Id: ""},
+	})
+
+	var matches = m.Match("", "Save the file!", 0.6, 0)
+	if len(matches) != 2 || matches[0].Index != 0 || matches[1].Index != 1 {
+		t.Fatalf("expected messages 0 and 1, got %v", matches)
+	}
+	if matches[0].Message.Id != "Save the file" || matches[0].Score <= matches[1].Score {
+		t.Errorf("unexpected matches %v", matches)
+	}
+
+	// The context breaks ties between identical msgids.
+	matches = m.Match("menu", "Open", 0.6, 0)
+	if len(matches) != 2 || matches[0].Index != 2 || matches[0].Score != 1 || matches[1].Score != contextPenalty {
+		t.Errorf("expected message 2 first, got %v", matches)
+	}
+	matches = m.Match("", "Open", 0.6, 1)
+	if len(matches) != 1 || matches[0].Index != 3 {
+		t.Errorf("expected message 3, got %v", matches)
+	}
+
+	if matches = m.Match("", "Nothing like it", 0.6, 0); len(matches) != 0 {
+		t.Errorf("expected no match, got %v", matches)
+	}
+}
+
+func TestMatcherLarge(t *testing.T) {
+	var msgs []Message
+	for i := 0; i < 100000; i++ {
+		msgs = append(msgs, Message{Id: fmt.Sprintf("Message number %d of the catalog", i)})
+	}
+	var m = NewMatcher(msgs)
+	var matches = m.Match("", "Message number 12345 of the catalog!", 0.9, 1)
+	if len(matches) != 1 || matches[0].Index != 12345 {
+		t.Errorf("expected message 12345, got %v", matches)
+	}
+
+	// Every message shares most trigrams with the query, yet the best match
+	// is found among a bounded number of candidates.
+	matches = m.Match("", "Message number 99999 of the catalog", 0.6, 3)
+	if len(matches) != 3 || matches[0].Index != 99999 || matches[0].Score != 1 {
+		t.Errorf("expected message 99999 first, got %v", matches)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		expected float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"abcd", "abxd", 0.75},
+		{"žluť", "žlut", 0.75},
+	}
+	for _, test := range tests {
+		if actual := similarity(test.a, test.b, 0); actual != test.expected {
+			t.Errorf("similarity(%q, %q) = %v, expected %v", test.a, test.b, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package po
+
+// DefaultFuzzyThreshold is the minimum similarity of a fuzzy match, as used
+// by msgmerge.
+const DefaultFuzzyThreshold = 0.6
+
+// MergeOptions controls how Merge updates translations.
+type MergeOptions struct {
+	// NoFuzzyMatching disables reusing translations of similar messages,
+	// like the --no-fuzzy-matching option of msgmerge.
+	NoFuzzyMatching bool
+
+	// FuzzyThreshold is the minimum similarity, between 0 and 1, of a fuzzy
+	// match. DefaultFuzzyThreshold is used if zero.
+	FuzzyThreshold float64
+}
+
+// Merge updates the translations in def to the messages of the template ref,
+// as msgmerge does. The result has the messages of ref, in the same order:
+//
+//   - Messages also present in def keep their translation, translator
+//     comments and flags, with references, extracted comments and format
+//     flags refreshed from ref.
+//   - Messages whose msgid changed reuse the translation of the most similar
+//     message in def, and are flagged fuzzy with the previous msgctxt, msgid
+//     and msgid_plural recorded in their comment.
+//   - Other messages are added untranslated.
+//
+// Translated messages of def that are no longer in ref are kept at the end,
+// marked obsolete. The header is taken from def, with the POT-Creation-Date
+// of ref.
+func Merge(def File, ref File, opts MergeOptions) File {
+	var r = File{Header: append(Header(nil), def.Header...), Pluralize: def.Pluralize, PluralRule: def.PluralRule}
+	r.HeaderComment = def.HeaderComment
+	if len(r.Header) == 0 {
+		r.Header = append(Header(nil), ref.Header...)
+		r.HeaderComment = ref.HeaderComment
+	} else if date := ref.Header.Get("POT-Creation-Date"); date != "" {
+		r.Header.Set("POT-Creation-Date", date)
+	}
+	// The plural rule of def comes from its Plural-Forms or its Language.
+	var nplurals = 2
+	if r.PluralRule != nil {
+		nplurals = r.PluralRule.NPlurals
+	} else if rule, err := headerPluralRule(r.Header); err == nil && rule != nil {
+		nplurals = rule.NPlurals
+	}
+
+	var (
+		index = make(map[string]int, len(def.Messages))
+		used  = make([]bool, len(def.Messages))
+	)
+	for i, msg := range def.Messages {
+		var key = catalogKey(msg.Ctxt, msg.Id)
+		if j, ok := index[key]; !ok || def.Messages[j].Obsolete {
+			index[key] = i
+		}
+	}
+
+	// Translated messages are candidates for fuzzy matching.
+	var candidates []int
+	var matcher *Matcher
+	if !opts.NoFuzzyMatching {
+		var msgs []Message
+		for i, msg := range def.Messages {
+			if !msg.Obsolete && msg.Id != "" && msg.hasTranslation() {
+				candidates = append(candidates, i)
+				msgs = append(msgs, msg)
+			}
+		}
+		matcher = NewMatcher(msgs)
+	}
+
+	for _, refMsg := range ref.Messages {
+		if refMsg.Obsolete {
+			continue
+		}
+		if i, ok := index[catalogKey(refMsg.Ctxt, refMsg.Id)]; ok {
+			used[i] = true
+			r.Messages = append(r.Messages, mergeMessage(def.Messages[i], refMsg, nplurals))
+			continue
+		}
+		if matcher != nil {
+			if matches := matcher.Match(refMsg.Ctxt, refMsg.Id, opts.threshold(), 1); len(matches) > 0 {
+				var i = candidates[matches[0].Index]
+				used[i] = true
+				var defMsg = def.Messages[i]
+				var msg = mergeMessage(defMsg, refMsg, nplurals)
+				msg.setFuzzy()
+				msg.PrevCtxt, msg.PrevId, msg.PrevIdPlural = defMsg.Ctxt, defMsg.Id, defMsg.IdPlural
+				r.Messages = append(r.Messages, msg)
+				continue
+			}
+		}
+		var msg = refMsg
+		msg.TranslatorComments = nil
+		msg.Flags = formatFlags(refMsg.Flags)
+		msg.PrevCtxt, msg.PrevId, msg.PrevIdPlural = "", "", ""
+		msg.Str = emptyStr(refMsg.IdPlural != "", nplurals)
+		r.Messages = append(r.Messages, msg)
+	}
+
+	for i, msg := range def.Messages {
+		if used[i] || !msg.Obsolete && !msg.hasTranslation() {
+			continue
+		}
+		msg.Obsolete = true
+		msg.References, msg.ExtractedComments = nil, nil
+		r.Messages = append(r.Messages, msg)
+	}
+	return r
+}
+
+func (opts MergeOptions) threshold() float64 {
+	if opts.FuzzyThreshold <= 0 {
+		return DefaultFuzzyThreshold
+	}
+	return opts.FuzzyThreshold
+}
+
+// mergeMessage returns the message of the template with the translation of
+// defMsg.
+func mergeMessage(defMsg, refMsg Message, nplurals int) Message {
+	var msg = Message{
+		Comment: Comment{
+			TranslatorComments: defMsg.TranslatorComments,
+			ExtractedComments:  refMsg.ExtractedComments,
+			References:         refMsg.References,
+			Flags:              mergeFlags(defMsg.Flags, refMsg.Flags),
+		},
+		Ctxt:     refMsg.Ctxt,
+		Id:       refMsg.Id,
+		IdPlural: refMsg.IdPlural,
+		Str:      defMsg.Str,
+	}
+	if defMsg.HasFlag("fuzzy") {
+		msg.PrevCtxt, msg.PrevId, msg.PrevIdPlural = defMsg.PrevCtxt, defMsg.PrevId, defMsg.PrevIdPlural
+	}
+
+	if len(defMsg.Str) == 0 {
+		// A message without msgstr, as accepted by lenient parsing, is
+		// untranslated.
+		msg.Str = emptyStr(refMsg.IdPlural != "", nplurals)
+		return msg
+	}
+
+	// Reshape the translation if the message gained or lost its plural.
+	switch {
+	case refMsg.IdPlural != "" && defMsg.IdPlural == "":
+		msg.Str = emptyStr(true, nplurals)
+		for i := range msg.Str {
+			msg.Str[i] = defMsg.Str[0]
+		}
+		msg.setFuzzy()
+	case refMsg.IdPlural == "" && defMsg.IdPlural != "":
+		msg.Str = defMsg.Str[:1]
+		msg.setFuzzy()
+	}
+	return msg
+}
+
+// mergeFlags returns the flags of the translation, with the format flags
+// replaced by those of the template.
+func mergeFlags(defFlags, refFlags []string) []string {
+	var r []string
+	for _, flag := range defFlags {
+		if !isFormatFlag(flag) {
+			r = append(r, flag)
+		}
+	}
+	return append(r, formatFlags(refFlags)...)
+}
+
+// formatFlags returns the format flags, such as "c-format" or
+// "no-python-format", among the given flags.
+func formatFlags(flags []string) []string {
+	var r []string
+	for _, flag := range flags {
+		if isFormatFlag(flag) {
+			r = append(r, flag)
+		}
+	}
+	return r
+}
+
+func isFormatFlag(flag string) bool {
+	return len(flag) > len("-format") && flag[len(flag)-len("-format"):] == "-format"
+}
+
+// setFuzzy adds the fuzzy flag, if not present.
+func (m *Message) setFuzzy() {
+	if !m.HasFlag("fuzzy") {
+		m.Flags = append([]string{"fuzzy"}, m.Flags...)
+	}
+}
+
+// hasTranslation returns true if any msgstr of the message is filled in.
+func (m Message) hasTranslation() bool {
+	for _, str := range m.Str {
+		if str != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyStr returns the msgstr of an untranslated message.
+func emptyStr(plural bool, nplurals int) []string {
+	if !plural {
+		return []string{""}
+	}
+	return make([]string, nplurals)
+}
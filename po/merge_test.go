@@ -0,0 +1,233 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	var def, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"POT-Creation-Date: 2014-01-01 00:00+0000\n"
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+# Keep this comment
+#: old.go:1
+#, c-format
+msgid "Hello, %s"
+msgstr "Ahoj, %s"
+
+#: old.go:2
+msgid "Save the file to the disk"
+msgstr "Uložiť súbor na disk"
+
+msgid "egg"
+msgstr "vajce"
+
+msgid "Removed message"
+msgstr "Odstránená správa"
+
+msgid "Removed untranslated"
+msgstr ""
+
+#~ msgid "Revived"
+#~ msgstr "Oživené"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(strings.NewReader(`msgid ""
+msgstr ""
+"POT-Creation-Date: 2015-02-02 00:00+0000\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. extracted comment
+#: new.go:1
+#, go-format
+msgid "Hello, %s"
+msgstr ""
+
+#: new.go:2
+msgid "Save the files to the disk"
+msgstr ""
+
+#: new.go:3
+msgid "egg"
+msgid_plural "eggs"
+msgstr[0] ""
+msgstr[1] ""
+
+#: new.go:4
+msgid "Brand new"
+msgstr ""
+
+msgid "Revived"
+msgstr ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = `msgid ""
+msgstr ""
+"POT-Creation-Date: 2015-02-02 00:00+0000\n"
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+# Keep this comment
+#. extracted comment
+#: new.go:1
+#, go-format
+msgid "Hello, %s"
+msgstr "Ahoj, %s"
+
+#: new.go:2
+#, fuzzy
+#| msgid "Save the file to the disk"
+msgid "Save the files to the disk"
+msgstr "Uložiť súbor na disk"
+
+#: new.go:3
+#, fuzzy
+msgid "egg"
+msgid_plural "eggs"
+msgstr[0] "vajce"
+msgstr[1] "vajce"
+msgstr[2] "vajce"
+
+#: new.go:4
+msgid "Brand new"
+msgstr ""
+
+msgid "Revived"
+msgstr "Oživené"
+
+#~ msgid "Removed message"
+#~ msgstr "Odstránená správa"
+
+`
+	var buf bytes.Buffer
+	if _, err = Merge(def, ref, MergeOptions{}).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+
+	// Without fuzzy matching, the changed message is new and the old one obsolete.
+	var merged = Merge(def, ref, MergeOptions{NoFuzzyMatching: true})
+	if msg := merged.Messages[1]; msg.HasFlag("fuzzy") || msg.Str[0] != "" {
+		t.Errorf("expected untranslated message, got %v", msg)
+	}
+	var obsolete []string
+	for _, msg := range merged.Messages {
+		if msg.Obsolete {
+			obsolete = append(obsolete, msg.Id)
+		}
+	}
+	if !reflect.DeepEqual(obsolete, []string{"Save the file to the disk", "Removed message"}) {
+		t.Errorf("unexpected obsolete messages: %q", obsolete)
+	}
+}
+
+func TestMergeFlags(t *testing.T) {
+	var def, err = Parse(strings.NewReader(`#, fuzzy, c-format
+#| msgid "Hello %s!"
+msgid "Hello %s"
+msgstr "Hallo %s"
+
+#, c-format, fuzzy
+msgid "Save %d files"
+msgstr "%d Dateien speichern"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(strings.NewReader(`msgid "Hello %s"
+msgstr ""
+
+#, c-format
+msgid "Save %d files now"
+msgstr ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = `#, fuzzy
+#| msgid "Hello %s!"
+msgid "Hello %s"
+msgstr "Hallo %s"
+
+#, fuzzy, c-format
+#| msgid "Save %d files"
+msgid "Save %d files now"
+msgstr "%d Dateien speichern"
+
+`
+	var buf bytes.Buffer
+	if _, err = Merge(def, ref, MergeOptions{}).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+}
+
+func TestMergeLanguagePlurals(t *testing.T) {
+	var def, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Language: ru\n"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(strings.NewReader(`msgid "file"
+msgid_plural "files"
+msgstr[0] ""
+msgstr[1] ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var merged = Merge(def, ref, MergeOptions{})
+	if str := merged.Messages[0].Str; len(str) != 3 {
+		t.Errorf("expected 3 msgstr, got %q", str)
+	}
+}
+
+func TestMergeMissingMsgstr(t *testing.T) {
+	var def, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "egg"
+
+msgid "apple"
+msgid_plural "apples"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(strings.NewReader(`msgid "egg"
+msgid_plural "eggs"
+msgstr[0] ""
+msgstr[1] ""
+
+msgid "apple"
+msgstr ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var merged = Merge(def, ref, MergeOptions{})
+	var expected = [][]string{{"", ""}, {""}}
+	for i, msg := range merged.Messages {
+		if !reflect.DeepEqual(msg.Str, expected[i]) || msg.HasFlag("fuzzy") {
+			t.Errorf("%v: expected untranslated %q, got %q %v", msg.Id, expected[i], msg.Str, msg.Flags)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package po
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	moMagic        = 0x950412de
+	moHeaderSize   = 28 // size of the header in revision 0 files
+	moSysdepHeader = 48 // size of the header in files with minor revision 1
+	moSegmentsEnd  = 0xffffffff
+
+	// moContextSep separates the context from the msgid in MO files.
+	moContextSep = "\x04"
+)
+
+// ParseMO reads the content of a binary GNU MO file and returns the list of
+// messages. Both byte orders and minor revisions 0 and 1 are supported.
+// System-dependent strings are restored to their PO form, e.g. "%<PRIu64>".
+func ParseMO(r io.Reader) (File, error) {
+	var data, err = io.ReadAll(r)
+	if err != nil {
+		return File{}, err
+	}
+	var mo = moReader{data: data}
+	if err = mo.readHeader(); err != nil {
+		return File{}, err
+	}
+
+	var msgs []Message
+	for i := 0; i < mo.nstrings; i++ {
+		var orig = mo.str(mo.origTab + 8*i)
+		var trans = mo.str(mo.transTab + 8*i)
+		msgs = append(msgs, newMOMessage(orig, trans))
+	}
+	for i := 0; i < mo.nsysdep; i++ {
+		var orig = mo.sysdepStr(mo.u32(mo.origSysdepTab + 4*i))
+		var trans = mo.sysdepStr(mo.u32(mo.transSysdepTab + 4*i))
+		msgs = append(msgs, newMOMessage(orig, trans))
+	}
+	if mo.err != nil {
+		return File{}, mo.err
+	}
+	return newFile(msgs)
+}
+
+// newMOMessage decodes the original and translated strings of an MO entry.
+func newMOMessage(orig, trans string) Message {
+	var msg Message
+	if i := strings.Index(orig, moContextSep); i != -1 {
+		msg.Ctxt, orig = orig[:i], orig[i+1:]
+	}
+	if i := strings.IndexByte(orig, 0); i != -1 {
+		msg.Id, msg.IdPlural = orig[:i], orig[i+1:]
+	} else {
+		msg.Id = orig
+	}
+	msg.Str = strings.Split(trans, "\x00")
+	return msg
+}
+
+// moReader decodes the tables of an MO file.
+// The first error encountered is kept in err; later reads return zero values.
+type moReader struct {
+	data  []byte
+	order binary.ByteOrder
+	err   error
+
+	nstrings, origTab, transTab            int
+	nsegments, segmentsTab                 int
+	nsysdep, origSysdepTab, transSysdepTab int
+}
+
+func (mo *moReader) readHeader() error {
+	if len(mo.data) < moHeaderSize {
+		return fmt.Errorf("po: invalid MO file: too short")
+	}
+	switch {
+	case binary.LittleEndian.Uint32(mo.data) == moMagic:
+		mo.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(mo.data) == moMagic:
+		mo.order = binary.BigEndian
+	default:
+		return fmt.Errorf("po: invalid MO file: bad magic number")
+	}
+
+	var revision = mo.u32(4)
+	if major := revision >> 16; major > 1 {
+		return fmt.Errorf("po: unsupported MO file revision %d.%d", major, revision&0xffff)
+	}
+	mo.nstrings = mo.u32(8)
+	mo.origTab = mo.u32(12)
+	mo.transTab = mo.u32(16)
+	if revision&0xffff >= 1 && len(mo.data) >= moSysdepHeader {
+		mo.nsegments = mo.u32(28)
+		mo.segmentsTab = mo.u32(32)
+		mo.nsysdep = mo.u32(36)
+		mo.origSysdepTab = mo.u32(40)
+		mo.transSysdepTab = mo.u32(44)
+	}
+	if mo.nstrings < 0 || mo.nstrings > len(mo.data)/8 || mo.nsysdep < 0 || mo.nsysdep > len(mo.data)/4 {
+		return fmt.Errorf("po: invalid MO file: bad string count")
+	}
+	return mo.err
+}
+
+// u32 returns the integer at the given offset.
+func (mo *moReader) u32(off int) int {
+	if off < 0 || off+4 > len(mo.data) {
+		mo.fail("offset %d out of range", off)
+		return 0
+	}
+	return int(mo.order.Uint32(mo.data[off:]))
+}
+
+// str returns the string described by the (length, offset) pair at off.
+func (mo *moReader) str(off int) string {
+	var length, start = mo.u32(off), mo.u32(off + 4)
+	if start+length > len(mo.data) || start+length < start || length < 0 {
+		mo.fail("string at offset %d out of range", start)
+		return ""
+	}
+	return string(mo.data[start : start+length])
+}
+
+// sysdepStr assembles the system-dependent string at off, substituting the
+// segment names for the system-dependent parts.
+func (mo *moReader) sysdepStr(off int) string {
+	var (
+		buf    bytes.Buffer
+		static = mo.u32(off)
+	)
+	for pair := off + 4; ; pair += 8 {
+		var segsize, ref = mo.u32(pair), mo.u32(pair + 4)
+		if mo.err != nil {
+			return ""
+		}
+		if static+segsize > len(mo.data) || static+segsize < static || segsize < 0 {
+			mo.fail("system-dependent string at offset %d out of range", off)
+			return ""
+		}
+		buf.Write(mo.data[static : static+segsize])
+		static += segsize
+		if uint32(ref) == moSegmentsEnd {
+			break
+		}
+		if ref < 0 || ref >= mo.nsegments {
+			mo.fail("segment %d out of range", ref)
+			return ""
+		}
+		// msgfmt names the <inttypes.h> macros without their angle brackets,
+		// e.g. "PRIu64" for "%<PRIu64>".
+		var name = strings.TrimSuffix(mo.str(mo.segmentsTab+8*ref), "\x00")
+		if strings.HasPrefix(name, "PRI") {
+			name = "<" + name + ">"
+		}
+		buf.WriteString(name)
+	}
+	return strings.TrimSuffix(buf.String(), "\x00")
+}
+
+func (mo *moReader) fail(format string, args ...interface{}) {
+	if mo.err == nil {
+		mo.err = fmt.Errorf("po: invalid MO file: "+format, args...)
+	}
+}
+
+// MOOptions controls which messages are written to an MO file.
+type MOOptions struct {
+	IncludeFuzzy        bool // write messages flagged "fuzzy", like msgfmt --use-fuzzy
+	IncludeUntranslated bool // write messages with empty translations
+}
+
+// WriteMO writes the file as a binary GNU MO file, skipping fuzzy and
+// untranslated messages as msgfmt does. Obsolete messages are never written.
+func (f File) WriteMO(w io.Writer) (n int64, err error) {
+	return f.WriteMOWithOptions(w, MOOptions{})
+}
+
+// WriteMOWithOptions writes the file as a binary GNU MO file, including the
+// messages selected by opts. The output is little-endian, revision 0, with the
+// original strings sorted and a hash table for fast lookups.
+func (f File) WriteMOWithOptions(w io.Writer, opts MOOptions) (n int64, err error) {
+	type entry struct{ orig, trans string }
+	var entries []entry
+	if len(f.Header) > 0 {
+		entries = append(entries, entry{"", f.Header.String()})
+	}
+	for _, msg := range f.Messages {
+		if msg.Id == "" || msg.Obsolete ||
+			!opts.IncludeFuzzy && msg.HasFlag("fuzzy") ||
+			!opts.IncludeUntranslated && !msg.translated() {
+			continue
+		}
+		var orig = msg.Id
+		if msg.Ctxt != "" {
+			orig = msg.Ctxt + moContextSep + orig
+		}
+		if msg.IdPlural != "" {
+			orig += "\x00" + msg.IdPlural
+		}
+		entries = append(entries, entry{orig, strings.Join(msg.Str, "\x00")})
+	}
+	// The original strings are compared up to the first NUL, as strcmp does.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return moKey(entries[i].orig) < moKey(entries[j].orig)
+	})
+
+	var (
+		nstrings = len(entries)
+		hashSize = moHashSize(nstrings)
+		origTab  = moHeaderSize
+		transTab = origTab + 8*nstrings
+		hashTab  = transTab + 8*nstrings
+		strs     = hashTab + 4*hashSize
+		buf      = make([]byte, strs)
+		le       = binary.LittleEndian
+	)
+	le.PutUint32(buf[0:], moMagic)
+	le.PutUint32(buf[4:], 0)
+	le.PutUint32(buf[8:], uint32(nstrings))
+	le.PutUint32(buf[12:], uint32(origTab))
+	le.PutUint32(buf[16:], uint32(transTab))
+	le.PutUint32(buf[20:], uint32(hashSize))
+	le.PutUint32(buf[24:], uint32(hashTab))
+	for i, e := range entries {
+		le.PutUint32(buf[origTab+8*i:], uint32(len(e.orig)))
+		le.PutUint32(buf[origTab+8*i+4:], uint32(len(buf)))
+		buf = append(buf, e.orig+"\x00"...)
+	}
+	for i, e := range entries {
+		le.PutUint32(buf[transTab+8*i:], uint32(len(e.trans)))
+		le.PutUint32(buf[transTab+8*i+4:], uint32(len(buf)))
+		buf = append(buf, e.trans+"\x00"...)
+	}
+	for i, e := range entries {
+		var (
+			hash = moHash(moKey(e.orig))
+			idx  = hash % uint32(hashSize)
+			incr = 1 + hash%uint32(hashSize-2)
+		)
+		for le.Uint32(buf[hashTab+4*int(idx):]) != 0 {
+			if idx >= uint32(hashSize)-incr {
+				idx -= uint32(hashSize) - incr
+			} else {
+				idx += incr
+			}
+		}
+		le.PutUint32(buf[hashTab+4*int(idx):], uint32(i+1))
+	}
+
+	var written int
+	written, err = w.Write(buf)
+	return int64(written), err
+}
+
+// translated returns true if every msgstr of the message is filled in.
+func (m Message) translated() bool {
+	for _, str := range m.Str {
+		if str == "" {
+			return false
+		}
+	}
+	return len(m.Str) > 0
+}
+
+// moKey returns the part of an original string that is hashed and sorted on:
+// the context and msgid, without the msgid_plural.
+func moKey(orig string) string {
+	if i := strings.IndexByte(orig, 0); i != -1 {
+		return orig[:i]
+	}
+	return orig
+}
+
+// moHash is the hashpjw function used by GNU gettext for MO hash tables.
+func moHash(s string) uint32 {
+	var hval uint32
+	for i := 0; i < len(s); i++ {
+		hval = hval<<4 + uint32(s[i])
+		if g := hval & (0xf << 28); g != 0 {
+			hval ^= g >> 24
+			hval ^= g
+		}
+	}
+	return hval
+}
+
+// moHashSize returns the hash table size msgfmt uses for n strings: the
+// smallest prime that is at least 4n/3, and no less than 3.
+func moHashSize(n int) int {
+	var size = n * 4 / 3
+	if size < 3 {
+		return 3
+	}
+	for !isPrime(size) {
+		size++
+	}
+	return size
+}
+
+func isPrime(n int) bool {
+	if n%2 == 0 {
+		return n == 2
+	}
+	for d := 3; d*d <= n; d += 2 {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
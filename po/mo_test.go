@@ -0,0 +1,257 @@
+package po
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildMO assembles an MO file with the given string pairs and, if sysdep is
+// non-nil, system-dependent strings referencing the segment "PRIu64", named
+// without angle brackets as msgfmt writes it.
+func buildMO(order binary.ByteOrder, pairs [][2]string, sysdep [][2]string) []byte {
+	var revision uint32
+	var headerSize = moHeaderSize
+	if sysdep != nil {
+		revision, headerSize = 1, moSysdepHeader
+	}
+	var (
+		n        = len(pairs)
+		origTab  = headerSize
+		transTab = origTab + 8*n
+		strs     = transTab + 8*n
+		tables   = make([]byte, 16*n)
+		data     []byte
+	)
+	for i, pair := range pairs {
+		for j, s := range pair {
+			var off = 8*i + 8*n*j
+			order.PutUint32(tables[off:], uint32(len(s)))
+			order.PutUint32(tables[off+4:], uint32(strs+len(data)))
+			data = append(data, s+"\x00"...)
+		}
+	}
+
+	var sysdepData []byte
+	var header = make([]byte, headerSize)
+	if sysdep != nil {
+		// Segments: [segment name descriptor], then the sysdep tables and the
+		// sysdep_string structures, then the static strings.
+		var (
+			base       = strs + len(data)
+			segTab     = base
+			origSysTab = segTab + 8
+			transSys   = origSysTab + 4*len(sysdep)
+			structs    = transSys + 4*len(sysdep)
+			strsStart  = structs + 2*len(sysdep)*20
+			segName    = "PRIu64"
+			u32        = func(v int) []byte { var b = make([]byte, 4); order.PutUint32(b, uint32(v)); return b }
+			statics    []byte
+			structData []byte
+			offsets    [2][]byte
+		)
+		var nameOff = strsStart
+		statics = append(statics, segName+"\x00"...)
+		sysdepData = append(sysdepData, u32(len(segName)+1)...)
+		sysdepData = append(sysdepData, u32(nameOff)...)
+		for _, pair := range sysdep {
+			for j, s := range pair {
+				// s contains a single "%" marking where the segment goes.
+				var i = bytes.IndexByte([]byte(s), '%') + 1
+				offsets[j] = append(offsets[j], u32(structs+len(structData))...)
+				structData = append(structData, u32(strsStart+len(statics))...)
+				structData = append(structData, u32(i)...)
+				structData = append(structData, u32(0)...)
+				structData = append(structData, u32(len(s)-i+1)...)
+				structData = append(structData, u32(moSegmentsEnd)...)
+				statics = append(statics, s+"\x00"...)
+			}
+		}
+		sysdepData = append(sysdepData, offsets[0]...)
+		sysdepData = append(sysdepData, offsets[1]...)
+		sysdepData = append(sysdepData, structData...)
+		sysdepData = append(sysdepData, statics...)
+		order.PutUint32(header[28:], 1)
+		order.PutUint32(header[32:], uint32(segTab))
+		order.PutUint32(header[36:], uint32(len(sysdep)))
+		order.PutUint32(header[40:], uint32(origSysTab))
+		order.PutUint32(header[44:], uint32(transSys))
+	}
+	order.PutUint32(header[0:], moMagic)
+	order.PutUint32(header[4:], revision)
+	order.PutUint32(header[8:], uint32(n))
+	order.PutUint32(header[12:], uint32(origTab))
+	order.PutUint32(header[16:], uint32(transTab))
+
+	var mo = append(header, tables...)
+	mo = append(mo, data...)
+	return append(mo, sysdepData...)
+}
+
+func TestParseMO(t *testing.T) {
+	var pairs = [][2]string{
+		{"", "Language: sk\nPlural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"},
+		{"The number of eggs you need.\x04You have one egg\x00You have {$EGGS_2} eggs",
+			"zYou zhave zone zegg\x00zYou zhave zfew zeggs\x00zYou zhave z{$EGGS_2} zeggs"},
+		{"hello", "ahoj"},
+	}
+	var expected = []Message{
+		{
+			Ctxt:     "The number of eggs you need.",
+			Id:       "You have one egg",
+			IdPlural: "You have {$EGGS_2} eggs",
+			Str:      []string{"zYou zhave zone zegg", "zYou zhave zfew zeggs", "zYou zhave z{$EGGS_2} zeggs"},
+		},
+		{Id: "hello", Str: []string{"ahoj"}},
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		var f, err = ParseMO(bytes.NewReader(buildMO(order, pairs, nil)))
+		if err != nil {
+			t.Errorf("%v: %v", order, err)
+			continue
+		}
+		if f.Header.Get("Language") != "sk" {
+			t.Errorf("%v: expected header, got %v", order, f.Header)
+		}
+		if f.Pluralize == nil || f.Pluralize(3) != 1 {
+			t.Errorf("%v: expected plural selector from header", order)
+		}
+		if !reflect.DeepEqual(expected, f.Messages) {
+			t.Errorf("%v: expected msgs:\n%v\ngot msgs:\n%v", order, expected, f.Messages)
+		}
+	}
+}
+
+func TestParseMOSysdep(t *testing.T) {
+	var mo = buildMO(binary.LittleEndian, [][2]string{{"a", "b"}},
+		[][2]string{{"% bytes", "% bajtov"}})
+	var f, err = ParseMO(bytes.NewReader(mo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Message{
+		{Id: "a", Str: []string{"b"}},
+		{Id: "%<PRIu64> bytes", Str: []string{"%<PRIu64> bajtov"}},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, f.Messages)
+	}
+}
+
+func TestParseMOInvalid(t *testing.T) {
+	var valid = buildMO(binary.LittleEndian, [][2]string{{"a", "b"}}, nil)
+	var tests = [][]byte{
+		nil,
+		[]byte("not an mo file at all, definitely not"),
+		valid[:len(valid)-3],
+	}
+	for _, test := range tests {
+		if _, err := ParseMO(bytes.NewReader(test)); err == nil {
+			t.Errorf("%q: expected error", test)
+		}
+	}
+}
+
+func TestWriteMO(t *testing.T) {
+	var f = File{
+		Header: Header{{"Language", "sk"}},
+		Messages: []Message{
+			{Id: "zebra", Str: []string{"zebra-sk"}},
+			{Id: "apple", Str: []string{"jablko"}},
+			{Ctxt: "menu", Id: "Open", Str: []string{"Otvoriť"}},
+			{Id: "egg", IdPlural: "eggs", Str: []string{"vajce", "vajcia", "vajec"}},
+			{Id: "untranslated", Str: []string{""}},
+			{Comment: Comment{Flags: []string{"fuzzy"}}, Id: "fuzzy", Str: []string{"neisté"}},
+		},
+	}
+	var buf bytes.Buffer
+	var n, err = f.WriteMO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("n (%v) != buf length (%v)", n, buf.Len())
+	}
+
+	actual, err := ParseMO(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Message{
+		{Id: "apple", Str: []string{"jablko"}},
+		{Id: "egg", IdPlural: "eggs", Str: []string{"vajce", "vajcia", "vajec"}},
+		{Ctxt: "menu", Id: "Open", Str: []string{"Otvoriť"}},
+		{Id: "zebra", Str: []string{"zebra-sk"}},
+	}
+	if !reflect.DeepEqual(expected, actual.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, actual.Messages)
+	}
+	if actual.Header.Get("Language") != "sk" {
+		t.Errorf("expected header, got %v", actual.Header)
+	}
+
+	// Every key must be reachable through the hash table.
+	var data = buf.Bytes()
+	var le = binary.LittleEndian
+	var hashSize, hashTab = le.Uint32(data[20:]), le.Uint32(data[24:])
+	for i, key := range []string{"", "apple", "egg", "menu\x04Open", "zebra"} {
+		var hash = moHash(key)
+		var idx, incr = hash % hashSize, 1 + hash%(hashSize-2)
+		for {
+			var j = le.Uint32(data[hashTab+4*idx:])
+			if j == 0 {
+				t.Errorf("%q not found in hash table", key)
+				break
+			}
+			if j == uint32(i+1) {
+				break
+			}
+			idx = (idx + incr) % hashSize
+		}
+	}
+
+	buf.Reset()
+	if _, err = f.WriteMOWithOptions(&buf, MOOptions{IncludeFuzzy: true, IncludeUntranslated: true}); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err = ParseMO(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(actual.Messages) != len(f.Messages) {
+		t.Errorf("expected %v messages, got %v", len(f.Messages), len(actual.Messages))
+	}
+}
+
+func TestWriteMOFuzzyFlags(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`msgid "Hello %s"
+msgstr "Hallo %s"
+
+#, fuzzy, c-format
+msgid "Goodbye %s"
+msgstr "Tschüss %s"
+
+#, c-format, fuzzy
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteMO(&buf); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ParseMO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Message{{Id: "Hello %s", Str: []string{"Hallo %s"}}}
+	if !reflect.DeepEqual(expected, actual.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, actual.Messages)
+	}
+}
@@ -1,6 +1,10 @@
 package po
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
 
 // PluralSelector returns the appropriate plural case to use, given a quantity.
 type PluralSelector func(n int) int
@@ -53,7 +57,7 @@ var langNames = map[string]string{
 	"bs":    "Bosnian",
 }
 
-// TODO: Fall back to these if Plural-Forms is not specified.
+// pluralExprs are the Plural-Forms used for files of a Language without one.
 var pluralExprs = map[string]string{
 	"ja":    "nplurals=1; plural=0;",
 	"vi":    "nplurals=1; plural=0;",
@@ -128,27 +132,114 @@ func stripSpace(m map[string]PluralSelector) map[string]PluralSelector {
 	return r
 }
 
-// lookupPluralSelectors looks up the given plural form from the set of known ones.
-// nil is returned if the plural form was not recognized.
-func lookupPluralSelector(pluralForms string) PluralSelector {
-	return pluralSelectors[strings.Replace(pluralForms, " ", "", -1)]
+// lookupPluralSelector returns the selector for the given plural forms. The
+// set of known ones is consulted first, falling back to compiling the
+// expression. An error is returned if the plural forms could not be parsed.
+func lookupPluralSelector(pluralForms string) (PluralSelector, error) {
+	if selector, ok := pluralSelectors[strings.Replace(pluralForms, " ", "", -1)]; ok {
+		return selector, nil
+	}
+	var _, selector, err = ParsePluralForms(pluralForms)
+	return selector, err
 }
 
 // PluralSelectorForLanguage returns the appropriate plural selector for the
 // provided languge code. The code can be either the too letter code ("en") or
-// the 5 character variant ("en_GB")
+// the 5 character variant ("en_GB"), or any locale understood by ParseLocale,
+// which falls back to less specific locales as Locale.Fallbacks does.
+// Languages missing from the table of GNU gettext Plural-Forms use the rules
+// of the Unicode CLDR, as ParseCLDRPluralRule does.
 func PluralSelectorForLanguage(lang string) PluralSelector {
-	lang = strings.Replace(lang, "-", "_", -1)
-	if pluralForms, found := pluralExprs[lang]; found {
-		return lookupPluralSelector(pluralForms)
+	if pluralForms, found := languagePluralForms(lang); found {
+		var selector, _ = lookupPluralSelector(pluralForms)
+		return selector
 	}
-	if len(lang) > 2 && lang[2] == '_' {
-		// Naively trim the input
-		if pluralForms, found := pluralExprs[lang[:2]]; found {
-			return lookupPluralSelector(pluralForms)
+	return cldrRuleForLanguage(lang).pluralize()
+}
+
+// languagePluralForms returns the Plural-Forms of the given language code.
+func languagePluralForms(lang string) (string, bool) {
+	for _, name := range localeFallbacks(lang) {
+		if pluralForms, found := pluralExprs[name]; found {
+			return pluralForms, true
 		}
 	}
-	return nil
+	return "", false
+}
+
+// PluralRule describes the plural forms of a language, as given by the
+// Plural-Forms header: how many there are, and how to select one for a
+// quantity.
+type PluralRule struct {
+	NPlurals int    // number of plural forms
+	Expr     string // C expression giving the form for n, e.g. "(n != 1)"
+
+	// Categories are the CLDR plural categories of the forms, such as "one"
+	// and "other", for rules made from CLDR data.
+	Categories []string
+
+	selector PluralSelector
+	cldr     []cldrCondition // conditions by CLDR category, if known
+
+	once     sync.Once      // compiles Expr, for rules not made by ParsePluralRule
+	compiled PluralSelector // the result
+}
+
+// ParsePluralRule parses a Plural-Forms header value, such as
+// "nplurals=2; plural=(n != 1);", as ParsePluralForms does.
+func ParsePluralRule(pluralForms string) (*PluralRule, error) {
+	var nplurals, expr, selector, err = parsePluralForms(pluralForms)
+	if err != nil {
+		return nil, err
+	}
+	if known, ok := pluralSelectors[strings.Replace(pluralForms, " ", "", -1)]; ok {
+		selector = known
+	}
+	return &PluralRule{NPlurals: nplurals, Expr: expr, selector: selector}, nil
+}
+
+// PluralRuleForLanguage returns the plural rule for the provided language
+// code, as PluralSelectorForLanguage does, or nil if it is not known.
+func PluralRuleForLanguage(lang string) *PluralRule {
+	if pluralForms, found := languagePluralForms(lang); found {
+		var rule, _ = ParsePluralRule(pluralForms)
+		return withCLDR(rule, lang)
+	}
+	return cldrRuleForLanguage(lang)
+}
+
+// Select returns the index of the plural form to use for the quantity n.
+// Rules not made by ParsePluralRule are compiled on the first call, so their
+// NPlurals and Expr must not change afterwards.
+func (r *PluralRule) Select(n int) int {
+	if r.selector != nil {
+		return r.selector(n)
+	}
+	r.once.Do(func() {
+		if rule, err := ParsePluralRule(r.String()); err == nil {
+			r.compiled = rule.selector
+		}
+	})
+	if r.compiled == nil {
+		return 0
+	}
+	return r.compiled(n)
+}
+
+// pluralize returns the selector of the rule, or nil if there is no rule.
+func (r *PluralRule) pluralize() PluralSelector {
+	switch {
+	case r == nil:
+		return nil
+	case r.selector == nil:
+		return r.Select
+	}
+	return r.selector
+}
+
+// String returns the rule in the syntax of the Plural-Forms header.
+func (r *PluralRule) String() string {
+	return "nplurals=" + strconv.Itoa(r.NPlurals) + "; plural=" + r.Expr + ";"
 }
 
 func plural0(n int) int {
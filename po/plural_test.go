@@ -16,6 +16,9 @@ func TestPluralSelectorForLanguage(t *testing.T) {
 		{"pt", pluralNeq1},
 		{"pt_BR", pluralGt1},
 		{"pt-BR", pluralGt1},
+		{"pt_BR.UTF-8", pluralGt1},
+		{"pt-Latn-BR", pluralGt1},
+		{"de_DE.UTF-8@euro", pluralNeq1},
 		{"tlh", nil},
 	}
 	for _, test := range tests {
@@ -29,3 +32,151 @@ func TestPluralSelectorForLanguage(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePluralForms(t *testing.T) {
+	for pluralForms, expected := range pluralSelectors {
+		var nplurals, actual, err = ParsePluralForms(pluralForms)
+		if err != nil {
+			t.Errorf("%v: %v", pluralForms, err)
+			continue
+		}
+		if nplurals < 1 {
+			t.Errorf("%v: nplurals = %v", pluralForms, nplurals)
+		}
+		for n := 0; n < 1000; n++ {
+			if expected(n) != actual(n) {
+				t.Errorf("%v: n=%v expected %v, got %v", pluralForms, n, expected(n), actual(n))
+				break
+			}
+		}
+	}
+}
+
+func TestParsePluralFormsVariants(t *testing.T) {
+	var tests = []struct {
+		pluralForms string
+		nplurals    int
+		expected    PluralSelector
+	}{
+		{"nplurals=2; plural=n != 1", 2, pluralNeq1},
+		{"nplurals=2; plural=((n != 1));", 2, pluralNeq1},
+		{"plural=(n>1); nplurals=2;", 2, pluralGt1},
+		{"nplurals=3; plural=(n%10==1 && n%100!=11) ? 0 : ((n != 0) ? 1 : 2);", 3, pluralLatvian},
+		{"nplurals=3; plural=(n==1 ? 0 : (n==2 ? 1 : 2));", 3, pluralIrish},
+		{"nplurals=3; plural=!(n!=1) ? 0 : n*2/4 == 1 - 0 ? 1 : 2;", 3, func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n*2/4 == 1:
+				return 1
+			}
+			return 2
+		}},
+		{"nplurals=2; plural=n/0 + n%0 + 5;", 2, plural0}, // out of range selects 0
+	}
+	for _, test := range tests {
+		var nplurals, actual, err = ParsePluralForms(test.pluralForms)
+		if err != nil {
+			t.Errorf("%v: %v", test.pluralForms, err)
+			continue
+		}
+		if nplurals != test.nplurals {
+			t.Errorf("%v: expected nplurals %v, got %v", test.pluralForms, test.nplurals, nplurals)
+		}
+		for n := 0; n < 200; n++ {
+			if test.expected(n) != actual(n) {
+				t.Errorf("%v: n=%v expected %v, got %v", test.pluralForms, n, test.expected(n), actual(n))
+				break
+			}
+		}
+	}
+}
+
+func TestParsePluralFormsErrors(t *testing.T) {
+	var tests = []struct {
+		pluralForms string
+		offset      int
+	}{
+		{"", 0},
+		{"nplurals=2;", 11},
+		{"plural=n;", 9},
+		{"nplurals=x; plural=n;", 9},
+		{"nplurals=2; plural=;", 19},
+		{"nplurals=2; plural=(n != 1;", 26},
+		{"nplurals=2; plural=n ? 1;", 24},
+		{"nplurals=2; plural=n & 1;", 21},
+		{"nplurals=2; plural=n 1;", 21},
+		{"nplurals=2; plural=x;", 19},
+		{"nplurals=2; plural=n; foo=1;", 22},
+		{"nplurals=2; nplurals=2; plural=n;", 12},
+	}
+	for _, test := range tests {
+		var _, _, err = ParsePluralForms(test.pluralForms)
+		var perr, ok = err.(*PluralFormsError)
+		if !ok {
+			t.Errorf("%q: expected *PluralFormsError, got %v", test.pluralForms, err)
+			continue
+		}
+		if perr.Offset != test.offset {
+			t.Errorf("%q: expected offset %v, got %v (%v)", test.pluralForms, test.offset, perr.Offset, perr)
+		}
+	}
+}
+
+func TestParsePluralRule(t *testing.T) {
+	var rule, err = ParsePluralRule("nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.NPlurals != 3 || rule.Expr != "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2" {
+		t.Errorf("unexpected rule %#v", rule)
+	}
+	if rule.String() != "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;" {
+		t.Errorf("unexpected String() %q", rule.String())
+	}
+	for n, expected := range []int{2, 0, 1, 1, 1, 2} {
+		if actual := rule.Select(n); actual != expected {
+			t.Errorf("n=%v: expected %v, got %v", n, expected, actual)
+		}
+	}
+
+	// Rules built by hand are compiled when used.
+	rule = &PluralRule{NPlurals: 2, Expr: "n > 1"}
+	if rule.Select(1) != 0 || rule.Select(2) != 1 {
+		t.Errorf("unexpected selection for %v", rule)
+	}
+	// They are compiled only once.
+	var compiled = reflect.ValueOf(rule.compiled).Pointer()
+	if rule.Select(3); compiled == 0 || reflect.ValueOf(rule.compiled).Pointer() != compiled {
+		t.Errorf("expected the rule to be compiled once")
+	}
+
+	if _, err = ParsePluralRule("nplurals=2;"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestPluralRuleForLanguage(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		nplurals int
+	}{
+		{"en", 2},
+		{"pt-BR", 2},
+		{"ja", 1},
+		{"ar", 6},
+		{"sr@latin", 3},
+		{"zh-Hant-TW", 1},
+		{"iw_IL", 2},
+		{"tlh", 0},
+	}
+	for _, test := range tests {
+		var rule = PluralRuleForLanguage(test.lang)
+		switch {
+		case rule == nil && test.nplurals != 0:
+			t.Errorf("%v: expected a rule", test.lang)
+		case rule != nil && rule.NPlurals != test.nplurals:
+			t.Errorf("%v: expected nplurals=%v, got %v", test.lang, test.nplurals, rule.NPlurals)
+		}
+	}
+}
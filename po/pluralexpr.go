@@ -0,0 +1,351 @@
+package po
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PluralFormsError describes a problem with a Plural-Forms string.
+type PluralFormsError struct {
+	Input  string // the full Plural-Forms string
+	Offset int    // byte offset of the problem within Input
+	Msg    string // description of the problem
+}
+
+func (e *PluralFormsError) Error() string {
+	return fmt.Sprintf("plural forms: %s at offset %d in %q", e.Msg, e.Offset, e.Input)
+}
+
+// ParsePluralForms parses a Plural-Forms header value, such as
+// "nplurals=2; plural=(n != 1);", returning the number of plural forms and a
+// selector that evaluates the plural expression.
+//
+// The expression may use the subset of C understood by GNU gettext: the
+// variable n, non-negative integer constants, parentheses, the ternary
+// operator, ||, &&, !, comparisons and the arithmetic operators + - * / %.
+// Division by zero evaluates to 0, and results outside of [0, nplurals) select
+// the first form, as GNU gettext does.
+func ParsePluralForms(pluralForms string) (nplurals int, selector PluralSelector, err error) {
+	nplurals, _, selector, err = parsePluralForms(pluralForms)
+	return nplurals, selector, err
+}
+
+// parsePluralForms parses a Plural-Forms header value, also returning the
+// text of the plural expression.
+func parsePluralForms(pluralForms string) (nplurals int, exprText string, selector PluralSelector, err error) {
+	var (
+		expr       *pluralNode
+		seenN      bool
+		seenPlural bool
+	)
+	for pos := 0; pos < len(pluralForms); {
+		var end = strings.IndexByte(pluralForms[pos:], ';')
+		if end == -1 {
+			end = len(pluralForms)
+		} else {
+			end += pos
+		}
+		var stmt = pluralForms[pos:end]
+		if strings.TrimSpace(stmt) != "" {
+			var eq = strings.IndexByte(stmt, '=')
+			if eq == -1 {
+				return 0, "", nil, pluralFormsErr(pluralForms, pos+skipSpace(stmt, 0), "expected name=value")
+			}
+			var valuePos = pos + eq + 1
+			switch name := strings.TrimSpace(stmt[:eq]); name {
+			case "nplurals":
+				if seenN {
+					return 0, "", nil, pluralFormsErr(pluralForms, pos+skipSpace(stmt, 0), "duplicate nplurals")
+				}
+				seenN = true
+				var value = strings.TrimSpace(stmt[eq+1:])
+				nplurals, err = strconv.Atoi(value)
+				if err != nil || nplurals < 1 {
+					return 0, "", nil, pluralFormsErr(pluralForms, valuePos+skipSpace(stmt[eq+1:], 0),
+						fmt.Sprintf("invalid nplurals %q", value))
+				}
+			case "plural":
+				if seenPlural {
+					return 0, "", nil, pluralFormsErr(pluralForms, pos+skipSpace(stmt, 0), "duplicate plural")
+				}
+				seenPlural = true
+				exprText = strings.TrimSpace(stmt[eq+1:])
+				var p = pluralParser{input: pluralForms, pos: valuePos, end: end}
+				if expr, err = p.parse(); err != nil {
+					return 0, "", nil, err
+				}
+			default:
+				return 0, "", nil, pluralFormsErr(pluralForms, pos+skipSpace(stmt, 0),
+					fmt.Sprintf("unknown field %q", name))
+			}
+		}
+		pos = end + 1
+	}
+	if !seenN {
+		return 0, "", nil, pluralFormsErr(pluralForms, len(pluralForms), "missing nplurals")
+	}
+	if !seenPlural {
+		return 0, "", nil, pluralFormsErr(pluralForms, len(pluralForms), "missing plural")
+	}
+
+	var eval = expr.compile()
+	return nplurals, exprText, func(n int) int {
+		var i = eval(n)
+		if i < 0 || i >= nplurals {
+			return 0
+		}
+		return i
+	}, nil
+}
+
+func pluralFormsErr(input string, offset int, msg string) error {
+	return &PluralFormsError{input, offset, msg}
+}
+
+// skipSpace returns the index of the first non-space byte in s at or after i.
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// pluralNode is a node in the syntax tree of a plural expression.
+type pluralNode struct {
+	op       string // operator, "n" or "num"
+	val      int    // value, for "num"
+	operands []*pluralNode
+}
+
+// compile turns the syntax tree into a function evaluating it.
+func (node *pluralNode) compile() func(n int) int {
+	switch node.op {
+	case "n":
+		return func(n int) int { return n }
+	case "num":
+		var val = node.val
+		return func(int) int { return val }
+	case "!":
+		var x = node.operands[0].compile()
+		return func(n int) int { return b2i(x(n) == 0) }
+	case "?":
+		var (
+			cond = node.operands[0].compile()
+			yes  = node.operands[1].compile()
+			no   = node.operands[2].compile()
+		)
+		return func(n int) int {
+			if cond(n) != 0 {
+				return yes(n)
+			}
+			return no(n)
+		}
+	}
+
+	var x, y = node.operands[0].compile(), node.operands[1].compile()
+	switch node.op {
+	case "||":
+		return func(n int) int { return b2i(x(n) != 0 || y(n) != 0) }
+	case "&&":
+		return func(n int) int { return b2i(x(n) != 0 && y(n) != 0) }
+	case "==":
+		return func(n int) int { return b2i(x(n) == y(n)) }
+	case "!=":
+		return func(n int) int { return b2i(x(n) != y(n)) }
+	case "<":
+		return func(n int) int { return b2i(x(n) < y(n)) }
+	case "<=":
+		return func(n int) int { return b2i(x(n) <= y(n)) }
+	case ">":
+		return func(n int) int { return b2i(x(n) > y(n)) }
+	case ">=":
+		return func(n int) int { return b2i(x(n) >= y(n)) }
+	case "+":
+		return func(n int) int { return x(n) + y(n) }
+	case "-":
+		return func(n int) int { return x(n) - y(n) }
+	case "*":
+		return func(n int) int { return x(n) * y(n) }
+	case "/":
+		return func(n int) int {
+			if d := y(n); d != 0 {
+				return x(n) / d
+			}
+			return 0
+		}
+	case "%":
+		return func(n int) int {
+			if d := y(n); d != 0 {
+				return x(n) % d
+			}
+			return 0
+		}
+	}
+	panic("po: unknown plural operator " + node.op)
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pluralBinaryOps lists the binary operators by increasing precedence.
+var pluralBinaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// pluralParser is a recursive descent parser for plural expressions.
+// It reports errors relative to the whole Plural-Forms string.
+type pluralParser struct {
+	input string
+	pos   int // current offset into input
+	end   int // offset at which the expression ends
+}
+
+func (p *pluralParser) parse() (*pluralNode, error) {
+	if p.peek() == 0 {
+		return nil, p.errorf("missing expression")
+	}
+	var node, err = p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:p.pos+1])
+	}
+	return node, nil
+}
+
+// ternary parses cond ? expr : expr, which is right associative.
+func (p *pluralParser) ternary() (*pluralNode, error) {
+	var cond, err = p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	yes, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(":") {
+		return nil, p.errorf("expected ':'")
+	}
+	no, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return &pluralNode{op: "?", operands: []*pluralNode{cond, yes, no}}, nil
+}
+
+// binary parses left associative binary operators of the given precedence
+// level or higher.
+func (p *pluralParser) binary(level int) (*pluralNode, error) {
+	if level == len(pluralBinaryOps) {
+		return p.unary()
+	}
+	var x, err = p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op = p.acceptAny(pluralBinaryOps[level])
+		if op == "" {
+			return x, nil
+		}
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &pluralNode{op: op, operands: []*pluralNode{x, y}}
+	}
+}
+
+func (p *pluralParser) unary() (*pluralNode, error) {
+	var c = p.peek()
+	switch {
+	case c == '!' && !p.lookingAt("!="):
+		p.pos++
+		var x, err = p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &pluralNode{op: "!", operands: []*pluralNode{x}}, nil
+	case c == '(':
+		p.pos++
+		var x, err = p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return x, nil
+	case c == 'n':
+		p.pos++
+		return &pluralNode{op: "n"}, nil
+	case c >= '0' && c <= '9':
+		var start = p.pos
+		for p.pos < p.end && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		var val, err = strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number")
+		}
+		return &pluralNode{op: "num", val: val}, nil
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", p.input[p.pos:p.pos+1])
+}
+
+// peek skips whitespace and returns the next byte, or 0 at the end.
+func (p *pluralParser) peek() byte {
+	for p.pos < p.end && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos >= p.end {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *pluralParser) lookingAt(tok string) bool {
+	p.peek()
+	return p.pos+len(tok) <= p.end && p.input[p.pos:p.pos+len(tok)] == tok
+}
+
+// accept consumes tok if it is next in the input.
+func (p *pluralParser) accept(tok string) bool {
+	if p.lookingAt(tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// acceptAny consumes and returns the first of toks that is next in the input.
+func (p *pluralParser) acceptAny(toks []string) string {
+	for _, tok := range toks {
+		if p.accept(tok) {
+			return tok
+		}
+	}
+	return ""
+}
+
+func (p *pluralParser) errorf(format string, args ...interface{}) error {
+	return pluralFormsErr(p.input, p.pos, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,164 @@
+package po
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// reduceLarge returns a quantity in the range of int with the same plural form
+// as n, for quantities too large to be represented: n % 1000000 + 1000000, as
+// the GNU gettext manual recommends for numbers exceeding unsigned long.
+func reduceLarge(n uint64) int {
+	if n <= math.MaxInt {
+		return int(n)
+	}
+	return int(n%1000000 + 1000000)
+}
+
+// SelectInt64 returns the index of the plural form to use for the quantity n.
+// Negative quantities use the form of their absolute value, and quantities
+// too large for an int are reduced as GNU gettext recommends, keeping their
+// last six digits.
+func (r *PluralRule) SelectInt64(n int64) int {
+	if n < 0 {
+		return r.SelectUint64(uint64(-(n + 1)) + 1)
+	}
+	return r.SelectUint64(uint64(n))
+}
+
+// SelectUint64 returns the index of the plural form to use for the quantity
+// n, as SelectInt64 does.
+func (r *PluralRule) SelectUint64(n uint64) int {
+	return r.Select(reduceLarge(n))
+}
+
+// SelectFloat returns the index of the plural form to use for the quantity f
+// formatted with the given number of fraction digits, as SelectDecimal does.
+// For example 1.5 with 2 digits is "1.50".
+func (r *PluralRule) SelectFloat(f float64, digits int) int {
+	var i, err = r.SelectDecimal(strconv.FormatFloat(f, 'f', digits, 64))
+	if err != nil {
+		// Infinities and NaN.
+		return r.Select(0)
+	}
+	return i
+}
+
+// SelectDecimal returns the index of the plural form to use for the decimal
+// number s, such as "1.5" or "-2.00", as it is displayed: the visible fraction
+// digits, including trailing zeros, may change the form. An exponent in the
+// compact decimal notation of the CLDR, as in "1.2c6", is allowed.
+//
+// Rules made from CLDR data, as those of PluralRuleForLanguage, select the
+// form of the category of s. Categories that only apply to fractions, such as
+// "other" in Russian, select the last form. Other rules follow GNU gettext,
+// which only knows of integers: the form of the integer part is used.
+func (r *PluralRule) SelectDecimal(s string) (int, error) {
+	var ops, err = decimalOperands(s)
+	if err != nil {
+		return 0, err
+	}
+	if r.cldr == nil || ops.v == 0 && ops.e == 0 {
+		return r.Select(reduceLarge(uint64(ops.i))), nil
+	}
+	var category = CLDRCategories[cldrSelect(r.cldr, ops)]
+	for i, c := range r.Categories {
+		if c == category {
+			return i, nil
+		}
+	}
+	return r.NPlurals - 1, nil
+}
+
+// decimalOperands returns the CLDR operands of a decimal number.
+func decimalOperands(s string) (cldrOperands, error) {
+	var syntaxErr = fmt.Errorf("po: invalid decimal number %q", s)
+	var num = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	var exp int
+	if i := strings.IndexAny(num, "ce"); i != -1 {
+		var err error
+		if exp, err = strconv.Atoi(num[i+1:]); err != nil || exp < 0 || exp > 18 {
+			return cldrOperands{}, syntaxErr
+		}
+		num = num[:i]
+	}
+	var intPart, fracPart, _ = strings.Cut(num, ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return cldrOperands{}, syntaxErr
+	}
+	// Move the decimal point to the right by the exponent.
+	for k := 0; k < exp; k++ {
+		if fracPart == "" {
+			intPart += "0"
+		} else {
+			intPart, fracPart = intPart+fracPart[:1], fracPart[1:]
+		}
+	}
+
+	var ops = cldrOperands{
+		v: int64(len(fracPart)),
+		e: int64(exp),
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart) > 18 {
+		// Too large: keep the last six digits, as reduceLarge does.
+		intPart = "1" + intPart[len(intPart)-6:]
+	}
+	if intPart != "" {
+		ops.i, _ = strconv.ParseInt(intPart, 10, 64)
+	}
+	var trimmed = strings.TrimRight(fracPart, "0")
+	ops.w = int64(len(trimmed))
+	if len(fracPart) > 18 {
+		fracPart, trimmed = fracPart[:18], trimmed[:min(len(trimmed), 18)]
+	}
+	if fracPart != "" {
+		ops.f, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+	if trimmed != "" {
+		ops.t, _ = strconv.ParseInt(trimmed, 10, 64)
+	}
+	ops.n = float64(ops.i)
+	if fracPart != "" {
+		var frac, _ = strconv.ParseFloat("0."+fracPart, 64)
+		ops.n += frac
+	}
+	return ops, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// withCLDR returns the rule with the CLDR data of the language attached, if
+// the CLDR rule selects the same forms for integers, so that decimals can be
+// selected by their category.
+func withCLDR(rule *PluralRule, lang string) *PluralRule {
+	if rule == nil || rule.cldr != nil {
+		return rule
+	}
+	var c = cldrRuleForLanguage(lang)
+	if c == nil || c.NPlurals != rule.NPlurals {
+		return rule
+	}
+	for n := 0; n <= cldrMaxSample; n++ {
+		if c.Select(n) != rule.Select(n) {
+			return rule
+		}
+	}
+	return &PluralRule{
+		NPlurals:   rule.NPlurals,
+		Expr:       rule.Expr,
+		Categories: c.Categories,
+		selector:   rule.selector,
+		cldr:       c.cldr,
+	}
+}
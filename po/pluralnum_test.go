@@ -0,0 +1,101 @@
+package po
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSelectLarge(t *testing.T) {
+	var rule = PluralRuleForLanguage("ru")
+	var tests = []struct {
+		n        uint64
+		expected int
+	}{
+		{1, 0},
+		{21, 0},
+		{1000002, 1},
+		{math.MaxUint64, 2},      // ...615
+		{math.MaxUint64 - 14, 0}, // ...601
+		{math.MaxUint64 - 13, 1}, // ...602
+	}
+	for _, test := range tests {
+		if actual := rule.SelectUint64(test.n); actual != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.n, test.expected, actual)
+		}
+	}
+	if actual := rule.SelectInt64(-21); actual != 0 {
+		t.Errorf("-21: expected 0, got %v", actual)
+	}
+	if actual := rule.SelectInt64(math.MinInt64); actual != 2 {
+		t.Errorf("MinInt64: expected 2, got %v", actual)
+	}
+}
+
+func TestSelectDecimal(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		s        string
+		expected int
+	}{
+		// CLDR rules attached to GNU gettext rules.
+		{"en", "1", 0},
+		{"en", "1.0", 1},
+		{"en", "1.5", 1},
+		{"fr", "1.5", 0},
+		{"fr", "2.0", 1},
+		{"cs", "1.5", 2}, // "many" only applies to fractions
+		{"ru", "2.5", 2},
+		{"ru", "2", 1},
+		// CLDR rules only.
+		{"hi", "0.5", 0},
+		{"mt", "-2", 1},
+		{"mt", "2.5", 4},
+		{"fil", "1.4", 1},
+		{"fil", "1.5", 0},
+		// Large and compact numbers.
+		{"en", "12345678901234567890123.0", 1},
+		{"ru", "12345678901234567890121", 0},
+		{"fr", "1.2c6", 1}, // "many" only applies to compact numbers
+		// The forms of Latvian are not in the order of the CLDR categories, so
+		// the integer part is used.
+		{"lv", "0.1", 2},
+	}
+	for _, test := range tests {
+		var rule = PluralRuleForLanguage(test.lang)
+		var actual, err = rule.SelectDecimal(test.s)
+		if err != nil {
+			t.Errorf("%v %v: %v", test.lang, test.s, err)
+		} else if actual != test.expected {
+			t.Errorf("%v %v: expected %v, got %v", test.lang, test.s, test.expected, actual)
+		}
+	}
+
+	var rule, _ = ParsePluralRule("nplurals=2; plural=(n != 1);")
+	if actual, _ := rule.SelectDecimal("1.5"); actual != 0 {
+		t.Errorf("expected the form of the integer part, got %v", actual)
+	}
+	for _, s := range []string{"", "-", ".5", "1.2.3", "1x", "1e", "1c-1", "1c99"} {
+		if _, err := rule.SelectDecimal(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestSelectFloat(t *testing.T) {
+	var rule = PluralRuleForLanguage("en")
+	var tests = []struct {
+		f        float64
+		digits   int
+		expected int
+	}{
+		{1, 0, 0},
+		{1, 1, 1},
+		{1.5, 1, 1},
+		{math.Inf(1), 0, 1},
+	}
+	for _, test := range tests {
+		if actual := rule.SelectFloat(test.f, test.digits); actual != test.expected {
+			t.Errorf("%v/%v: expected %v, got %v", test.f, test.digits, test.expected, actual)
+		}
+	}
+}
@@ -1,20 +1,21 @@
 package po
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
 	"io"
-	"net/textproto"
-	"sort"
+	"os"
 	"strings"
 )
 
 // File represents a PO file.
 type File struct {
-	Header    textproto.MIMEHeader
-	Messages  []Message
-	Pluralize PluralSelector
+	Header     Header
+	Messages   []Message
+	Pluralize  PluralSelector
+	PluralRule *PluralRule // from the Plural-Forms or Language header, if known
+
+	// HeaderComment holds the comments of the header entry, such as the title
+	// and copyright lines and the "fuzzy" flag of templates.
+	HeaderComment Comment
 }
 
 // Message stores a gettext message.
@@ -24,125 +25,182 @@ type Message struct {
 	Id       string   // msgid: untranslated singular string
 	IdPlural string   // msgid_plural: untranslated plural string
 	Str      []string // msgstr or msgstr[n]: translated strings
+	Obsolete bool     // entry is commented out with "#~"
 }
 
 // Comment stores meta-data from a gettext message.
+//
+// The previous strings of fuzzy messages are unquoted, as Ctxt, Id and
+// IdPlural are: `#| msgid "Old \"text\""` gives the PrevId `Old "text"`, not
+// the raw `"Old \"text\""`. Strings continued on several lines are joined,
+// and they are quoted and wrapped again when written.
 type Comment struct {
 	TranslatorComments []string
 	ExtractedComments  []string
 	References         []string
 	Flags              []string
-	PrevCtxt           string
-	PrevId             string
-	PrevIdPlural       string
+	PrevCtxt           string // "#| msgctxt": previous context, for fuzzy messages
+	PrevId             string // "#| msgid": previous untranslated string
+	PrevIdPlural       string // "#| msgid_plural": previous untranslated plural
+}
+
+// HasFlag returns true if the given flag, such as "fuzzy", is present.
+func (c Comment) HasFlag(flag string) bool {
+	for _, f := range c.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOptions controls how PO files are parsed.
+type ParseOptions struct {
+	Filename string // name of the file, reported in errors
+
+	// Strict makes unknown keywords, out-of-order or duplicate fields,
+	// non-contiguous msgstr indexes and missing msgid or msgstr fail the
+	// parse. Otherwise they are reported to Warn, if set, and tolerated.
+	Strict bool
+	Warn   func(*ParseError)
+
+	// MaxLineSize and MaxMessageSize limit the length in bytes of a line and
+	// of all the lines of a message, guarding against hostile input.
+	// Exceeding them fails with bufio.ErrTooLong and ErrMessageTooLong
+	// respectively. There is no limit if zero.
+	MaxLineSize    int
+	MaxMessageSize int
 }
 
 // Parse reads the content of a PO file and returns the list of messages.
+// Syntax errors are reported as a *ParseError.
 func Parse(r io.Reader) (File, error) {
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// ParseFile reads the PO file with the given name. Errors report the name.
+func ParseFile(filename string) (File, error) {
+	var f, err = os.Open(filename)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	return ParseWithOptions(f, ParseOptions{Filename: filename})
+}
+
+// ParseWithOptions reads the content of a PO file as directed by opts.
+func ParseWithOptions(r io.Reader, opts ParseOptions) (File, error) {
+	var dec = NewDecoderWithOptions(r, opts)
+	var header, err = dec.Header()
+	if err != nil {
+		return File{}, err
+	}
 	var msgs []Message
-	var scan = newScanner(r)
-	for scan.nextmsg() {
-		// NOTE: the source code order of these fields is important.
-		var msg = Message{
-			Comment: Comment{
-				TranslatorComments: scan.mul("# "),
-				ExtractedComments:  scan.mul("#."),
-				References:         scan.spc("#:"),
-				Flags:              scan.spc("#,"),
-				PrevCtxt:           scan.one("#| msgctxt"),
-				PrevId:             scan.one("#| msgid"),
-				PrevIdPlural:       scan.one("#| msgid_plural"),
-			},
-			Ctxt:     scan.quo("msgctxt"),
-			Id:       scan.quo("msgid"),
-			IdPlural: scan.quo("msgid_plural"),
-			Str:      scan.msgstr(),
+	for {
+		var msg, err = dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return File{}, err
 		}
 		msgs = append(msgs, msg)
 	}
-	if scan.Err() != nil {
-		return File{}, scan.Err()
-	}
+	return File{header, msgs, dec.pluralize, dec.rule, dec.headerComment}, nil
+}
 
+// newFile creates a File from the given messages, extracting the header from
+// the first message if present and selecting the plural function.
+func newFile(msgs []Message) (File, error) {
 	if len(msgs) == 0 {
 		return File{}, nil
 	}
 
-	var header textproto.MIMEHeader
-	if msgs[0].Id == "" && len(msgs[0].Str) == 1 {
-		var err error
-		header, err = textproto.NewReader(bufio.NewReader(strings.NewReader(msgs[0].Str[0]))).
-			ReadMIMEHeader()
-		if err != nil && err != io.EOF {
-			return File{}, err
-		}
+	var (
+		header  Header
+		comment Comment
+	)
+	if isHeader(msgs[0]) {
+		header, comment = ParseHeader(msgs[0].Str[0]), msgs[0].Comment
 		msgs = msgs[1:]
 	}
-
-	var pluralize PluralSelector
-	if pluralForms := header.Get("Plural-Forms"); pluralForms != "" {
-		pluralize = lookupPluralSelector(pluralForms)
-		if pluralize == nil {
-			return File{}, fmt.Errorf("unrecognized plural form selector: %v", pluralForms)
-		}
+	var rule, err = headerPluralRule(header)
+	if err != nil {
+		return File{}, err
 	}
-	if pluralize == nil {
-		pluralize = PluralSelectorForLanguage(header.Get("Language"))
+	return File{header, msgs, rule.pluralize(), rule, comment}, nil
+}
+
+// isHeader returns true if the message is a header entry.
+func isHeader(msg Message) bool {
+	return msg.Id == "" && len(msg.Str) == 1
+}
+
+// TemplatePluralForms is the placeholder Plural-Forms of the header of POT
+// files, to be filled in by translators. It stands for no plural rule.
+const TemplatePluralForms = "nplurals=INTEGER; plural=EXPRESSION;"
+
+// headerPluralRule returns the plural rule for the given header, from its
+// Plural-Forms or else its Language. It returns nil if neither is known.
+func headerPluralRule(header Header) (*PluralRule, error) {
+	if pluralForms := header.Get("Plural-Forms"); pluralForms != "" && !IsTemplatePluralForms(pluralForms) {
+		var rule, err = ParsePluralRule(pluralForms)
+		return withCLDR(rule, header.Get("Language")), err
 	}
+	return PluralRuleForLanguage(header.Get("Language")), nil
+}
 
-	return File{header, msgs, pluralize}, nil
+// IsTemplatePluralForms returns true if pluralForms is the placeholder
+// TemplatePluralForms, ignoring spaces.
+func IsTemplatePluralForms(pluralForms string) bool {
+	return strings.Replace(pluralForms, " ", "", -1) == strings.Replace(TemplatePluralForms, " ", "", -1)
 }
 
 // Write the PO file to a destination writer.
 func (f File) WriteTo(w io.Writer) (n int64, err error) {
-	var wr = newWriter()
-	// TODO: Probably better to make a type for the header and implement WriterTo
-	if len(f.Header) > 0 {
-		wr.quo("msgid ", "")
-		var keys []string
-		for k := range f.Header {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		var buf bytes.Buffer
-		for _, k := range keys {
-			buf.WriteString(k + ": " + f.Header.Get(k) + "\n")
-		}
-		wr.quo("msgstr ", buf.String())
-		wr.newline()
+	return f.WriteWithOptions(w, WriteOptions{})
+}
+
+// WriteWithOptions writes the PO file to a destination writer, formatted as
+// directed by opts. Untranslated plural messages are written with as many
+// empty msgstr[n] as the PluralRule of the file calls for.
+func (f File) WriteWithOptions(w io.Writer, opts WriteOptions) (n int64, err error) {
+	var enc = &Encoder{newBufferedWriter(w, opts)}
+	if len(f.Header) > 0 || !f.HeaderComment.empty() {
+		enc.WriteHeaderWithComment(f.Header, f.HeaderComment)
+	}
+	if f.PluralRule != nil {
+		enc.wr.nplurals = f.PluralRule.NPlurals
 	}
 	for _, msg := range f.Messages {
-		wr.from(msg)
-		wr.newline()
+		enc.WriteMessage(msg)
 	}
-	return wr.to(w)
+	return enc.wr.result()
 }
 
 // Write the PO Message to a destination writer.
 func (m Message) WriteTo(w io.Writer) (n int64, err error) {
-	var wr = newWriter()
-	wr.from(m.Comment)
-	wr.opt("msgctxt ", m.Ctxt)
-	wr.quo("msgid ", m.Id)
-	wr.opt("msgid_plural ", m.IdPlural)
-	if len(m.IdPlural) == 0 {
-		wr.msgstr(m.Str)
-	} else {
-		wr.plural(m.Str)
-	}
+	return m.WriteWithOptions(w, WriteOptions{})
+}
+
+// WriteWithOptions writes the PO Message to a destination writer, formatted as
+// directed by opts.
+func (m Message) WriteWithOptions(w io.Writer, opts WriteOptions) (n int64, err error) {
+	var wr = newBufferedWriter(w, opts)
+	wr.message(m)
+	return wr.result()
+}
 
-	return wr.to(w)
+// empty returns true if there are no comments.
+func (c Comment) empty() bool {
+	return len(c.TranslatorComments) == 0 && len(c.ExtractedComments) == 0 &&
+		len(c.References) == 0 && len(c.Flags) == 0 &&
+		c.PrevCtxt == "" && c.PrevId == "" && c.PrevIdPlural == ""
 }
 
 // Write the comment to the given writer.
 func (c Comment) WriteTo(w io.Writer) (n int64, err error) {
-	var wr = newWriter()
-	wr.mul("#  ", c.TranslatorComments)
-	wr.mul("#. ", c.ExtractedComments)
-	wr.spc("#: ", c.References)
-	wr.spc("#, ", c.Flags)
-	wr.one("#| msgctxt ", c.PrevCtxt)
-	wr.one("#| msgid ", c.PrevId)
-	wr.one("#| msgid_plural ", c.PrevIdPlural)
-	return wr.to(w)
+	var wr = newBufferedWriter(w, WriteOptions{})
+	wr.comment(c, "#| ")
+	return wr.result()
 }
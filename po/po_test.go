@@ -1,8 +1,12 @@
 package po
 
 import (
+	"bufio"
 	"bytes"
-	"net/textproto"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -48,17 +52,17 @@ msgstr ""
 `[1:]
 
 var file = File{
-	Header: textproto.MIMEHeader{
-		"Content-Transfer-Encoding": {"8bit"},
-		"Content-Type":              {"text/plain; charset=UTF-8"},
-		"Language":                  {"sk"},
-		"Language-Team":             {"Slovak <sk-i18n@lists.linux.sk>"},
-		"Last-Translator":           {"Marcel Telka <marcel@telka.sk>"},
-		"Mime-Version":              {"1.0"},
-		"Plural-Forms":              {"nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;"},
-		"Po-Revision-Date":          {"2014-05-10 18:15+0200"},
-		"Project-Id-Version":        {"GNU hello-java 0.19-rc1"},
-		"Report-Msgid-Bugs-To":      {"bug-gnu-gettext@gnu.org"},
+	Header: Header{
+		{"Content-Transfer-Encoding", "8bit"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Language", "sk"},
+		{"Language-Team", "Slovak <sk-i18n@lists.linux.sk>"},
+		{"Last-Translator", "Marcel Telka <marcel@telka.sk>"},
+		{"Mime-Version", "1.0"},
+		{"Plural-Forms", "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;"},
+		{"Po-Revision-Date", "2014-05-10 18:15+0200"},
+		{"Project-Id-Version", "GNU hello-java 0.19-rc1"},
+		{"Report-Msgid-Bugs-To", "bug-gnu-gettext@gnu.org"},
 	},
 	Messages: []Message{
 		{
@@ -131,3 +135,289 @@ func TestWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestParseUnknownPluralForms(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : ((n>=2 && n<=4) ? 1 : 2);\n"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, expected := range []int{2, 0, 1, 1, 1, 2} {
+		if actual := f.Pluralize(n); actual != expected {
+			t.Errorf("n=%v: expected %v, got %v", n, expected, actual)
+		}
+	}
+
+	_, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=n !! 1;\n"
+`))
+	if _, ok := err.(*PluralFormsError); !ok {
+		t.Errorf("expected *PluralFormsError, got %v", err)
+	}
+}
+
+var obsoletePo = `
+#: main.go:10
+msgid "Current"
+msgstr "Aktuálne"
+
+#. extracted comment
+#, fuzzy
+#~| msgid "Old text"
+#~ msgctxt "ctx"
+#~ msgid "Obsolete"
+#~ msgstr ""
+#~ "Line 1\n"
+#~ "Line 2"
+
+#~ msgid "egg"
+#~ msgid_plural "eggs"
+#~ msgstr[0] "vajce"
+#~ msgstr[1] "vajcia"
+
+`[1:]
+
+func TestObsolete(t *testing.T) {
+	var f, err = Parse(strings.NewReader(obsoletePo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Message{
+		{
+			Comment: Comment{References: []string{"main.go:10"}},
+			Id:      "Current",
+			Str:     []string{"Aktuálne"},
+		},
+		{
+			Comment: Comment{
+				ExtractedComments: []string{"extracted comment"},
+				Flags:             []string{"fuzzy"},
+				PrevId:            "Old text",
+			},
+			Ctxt:     "ctx",
+			Id:       "Obsolete",
+			Str:      []string{"Line 1\nLine 2"},
+			Obsolete: true,
+		},
+		{
+			Id:       "egg",
+			IdPlural: "eggs",
+			Str:      []string{"vajce", "vajcia"},
+			Obsolete: true,
+		},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, f.Messages)
+	}
+
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != obsoletePo {
+		t.Errorf("expected:\n%v\ngot:\n%v", obsoletePo, buf.String())
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`#, fuzzy, c-format
+#,no-wrap,	python-format
+msgid "Hello %s"
+msgstr "Hallo %d"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []string{"fuzzy", "c-format", "no-wrap", "python-format"}
+	if !reflect.DeepEqual(expected, f.Messages[0].Flags) {
+		t.Errorf("expected %q, got %q", expected, f.Messages[0].Flags)
+	}
+	if !f.Messages[0].HasFlag("fuzzy") {
+		t.Errorf("expected the fuzzy flag")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var tests = []struct {
+		po       string
+		expected ParseError
+	}{
+		{"msgid \"a\"\nmsgstr \"b\\q\"\n", ParseError{
+			Line: 2, Column: 10, Text: `msgstr "b\q"`, Field: "msgstr",
+		}},
+		{"msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[0] \"c\"\nmsgstr[1] \"d\n", ParseError{
+			Line: 4, Column: 13, Text: `msgstr[1] "d`, Field: "msgstr[1]",
+		}},
+		{"msgid \"\"\n\"ok\"\n\"bad\" x\nmsgstr \"\"\n", ParseError{
+			Line: 3, Column: 6, Text: `"bad" x`, Field: "msgid",
+		}},
+		{"#~ msgid \"a\"\n#~ msgstr b\n", ParseError{
+			Line: 2, Column: 11, Text: `#~ msgstr b`, Field: "msgstr",
+		}},
+	}
+	for _, test := range tests {
+		var _, err = Parse(strings.NewReader(test.po))
+		var perr, ok = err.(*ParseError)
+		if !ok {
+			t.Errorf("%q: expected *ParseError, got %v", test.po, err)
+			continue
+		}
+		perr.Err = nil
+		if !reflect.DeepEqual(test.expected, *perr) {
+			t.Errorf("%q: expected %+v, got %+v", test.po, test.expected, *perr)
+		}
+	}
+}
+
+func TestParseFileError(t *testing.T) {
+	var filename = filepath.Join(t.TempDir(), "sk.po")
+	if err := os.WriteFile(filename, []byte("msgid \"a\"\nmsgstr \"b\\q\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var _, err = ParseFile(filename)
+	if err == nil || !strings.HasPrefix(err.Error(), filename+":2:10: msgstr: ") {
+		t.Errorf("expected error for %v, got %v", filename, err)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	var tests = []struct {
+		po      string
+		line    int
+		problem string
+	}{
+		{"msgid \"a\"\nmsgstr \"b\"\nmsgid_plurall \"c\"\n", 3, `unknown keyword "msgid_plurall"`},
+		{"msgstr \"b\"\nmsgid \"a\"\n", 1, "missing msgid"},
+		{"msgid \"a\"\nmsgid \"b\"\nmsgstr \"c\"\n", 2, "duplicate msgid"},
+		{"msgid \"a\"\nmsgstr \"b\"\nmsgstr \"c\"\n", 3, "duplicate msgstr"},
+		{"msgid \"a\"\nmsgstr \"b\"\nmsgid_plural \"c\"\n", 3, "misplaced msgid_plural"},
+		{"msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[0] \"c\"\nmsgstr[2] \"d\"\n", 4, "non-contiguous msgstr index msgstr[2]"},
+		{"#: a.go:1\n\nmsgid \"a\"\nmsgstr \"b\"\n", 1, "missing msgid"},
+		{"msgid \"a\"\n\nmsgstr \"b\"\n", 1, "missing msgstr"},
+		{"msgid \"a\"\nmsgstr \"b\"\n\"c\"\n", 0, ""},
+		{"msgid \"a\"\nmsgstr \"b\"\n\n\"c\"\n", 4, "unexpected string"},
+		{"msgid \"a\"\nmsgstr \"b\"\nbogus\n", 3, `unknown keyword "bogus"`},
+		{"#foo\nmsgid \"a\"\nmsgstr \"b\"\n", 1, `unknown comment "#foo"`},
+		{"msgid \"a\"\nmsgid_plural \"b\"\nmsgstr \"c\"\n", 3, "msgstr without index in plural message"},
+		{"msgid \"a\"\nmsgstr[0] \"c\"\n", 2, "msgstr[0] in message without msgid_plural"},
+		{"msgctxt\t\"x\"\nmsgid\t\"a\"\nmsgstr\t\"b\"\n", 0, ""},
+		{"msgid \"a\"\nmsgid_plural\t\"b\"\nmsgstr[0]\t\"c\"\nmsgstr[1]\t\"d\"\n", 0, ""},
+	}
+	for _, test := range tests {
+		var _, err = ParseWithOptions(strings.NewReader(test.po), ParseOptions{Strict: true})
+		if test.problem == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", test.po, err)
+			}
+			continue
+		}
+		var perr, ok = err.(*ParseError)
+		if !ok {
+			t.Errorf("%q: expected *ParseError, got %v", test.po, err)
+			continue
+		}
+		if perr.Line != test.line || perr.Err.Error() != test.problem {
+			t.Errorf("%q: expected %q on line %v, got %v", test.po, test.problem, test.line, perr)
+		}
+
+		// The lenient mode reports the same problem as a warning.
+		var warnings []*ParseError
+		_, err = ParseWithOptions(strings.NewReader(test.po), ParseOptions{
+			Warn: func(err *ParseError) { warnings = append(warnings, err) },
+		})
+		if err != nil {
+			t.Errorf("%q: unexpected error in lenient mode: %v", test.po, err)
+		}
+		if len(warnings) == 0 || warnings[0].Err.Error() != test.problem {
+			t.Errorf("%q: expected warning %q, got %v", test.po, test.problem, warnings)
+		}
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`#, fuzzy
+#: a.go:1
+#. extracted
+#: b.go:2
+msgid "a"
+msgstr "b"
+bogus line
+msgid "c"
+msgstr "d"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Message{
+		{
+			Comment: Comment{
+				ExtractedComments: []string{"extracted"},
+				References:        []string{"a.go:1", "b.go:2"},
+				Flags:             []string{"fuzzy"},
+			},
+			Id:  "a",
+			Str: []string{"b"},
+		},
+		{Id: "c", Str: []string{"d"}},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, f.Messages)
+	}
+}
+
+func TestParseLongLines(t *testing.T) {
+	var long = strings.Repeat("<p>Hello, world!</p>", 10000)
+	var f, err = Parse(strings.NewReader("msgid \"" + long + "\"\nmsgstr \"" + long + "\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Messages) != 1 || f.Messages[0].Id != long || f.Messages[0].Str[0] != long {
+		t.Errorf("long message not parsed correctly")
+	}
+
+	_, err = ParseWithOptions(strings.NewReader("msgid \"a\"\nmsgstr \""+long+"\"\n"),
+		ParseOptions{MaxLineSize: 1024})
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 2 || !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("expected line too long on line 2, got %v", err)
+	}
+
+	var multi = "msgid \"a\"\nmsgstr \"\"\n" + strings.Repeat("\"0123456789\"\n", 100)
+	_, err = ParseWithOptions(strings.NewReader(multi), ParseOptions{MaxMessageSize: 500})
+	if !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("expected message too long, got %v", err)
+	}
+	if _, err = ParseWithOptions(strings.NewReader(multi), ParseOptions{MaxMessageSize: 5000}); err != nil {
+		t.Error(err)
+	}
+
+	// Lines between messages are not counted.
+	var spaced = "msgid \"a\"\nmsgstr \"\"\n" + strings.Repeat("\n#\n", 200) + "msgid \"b\"\nmsgstr \"\"\n"
+	if _, err = ParseWithOptions(strings.NewReader(spaced), ParseOptions{MaxMessageSize: 100}); err != nil {
+		t.Error(err)
+	}
+
+	// Input is not read beyond the limit.
+	var huge = &countingReader{r: strings.NewReader("msgid \"a\"\nmsgstr \"\"\n" + strings.Repeat("\"0123456789\"\n", 200000))}
+	_, err = ParseWithOptions(huge, ParseOptions{MaxMessageSize: 1000})
+	if !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("expected message too long, got %v", err)
+	}
+	if huge.n > 64*1024 {
+		t.Errorf("expected reading to stop at the limit, read %v bytes", huge.n)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	var n, err = c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -3,7 +3,9 @@ package po
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -12,28 +14,130 @@ import (
 // it is a mirror of the writer.
 type scanner struct {
 	*bufio.Scanner
-	hasNext bool
-	err     error
+	opts     ParseOptions
+	err      error
+	eof      bool   // no more lines are available
+	lineno   int    // number of the current line
+	raw      []byte // current line, as read
+	line     []byte // current line, without any obsolete marker
+	obsolete bool   // current line was marked obsolete with "#~"
+
+	// state of the current message
+	msgline int      // number of the first line of the message
+	msgtext string   // content of the first line of the message
+	msgsize int      // number of bytes read for the message
+	fields  []string // keywords read, e.g. "msgid" or "msgstr[1]"
+}
+
+func newScanner(r io.Reader, opts ParseOptions) *scanner {
+	var s = &scanner{Scanner: bufio.NewScanner(r), opts: opts}
+	// Lines may be arbitrarily long unless limited, so the buffer is allowed to
+	// grow beyond the bufio default of 64KB.
+	var max = opts.MaxLineSize
+	if max <= 0 {
+		max = math.MaxInt
+	}
+	s.Scanner.Buffer(make([]byte, 0, 4096), max)
+	return s
+}
+
+// Scan advances to the next line.
+// Obsolete lines are presented without their "#~" marker, so that "#~ msgid"
+// reads as "msgid" and "#~| msgid" reads as "#| msgid".
+// Scanning stops at the first error, so that no more input is read.
+func (s *scanner) Scan() bool {
+	if s.eof || s.err != nil || !s.Scanner.Scan() {
+		s.stop()
+		return false
+	}
+	s.lineno++
+	s.raw = s.Scanner.Bytes()
+	s.line, s.obsolete = s.raw, false
+	if bytes.HasPrefix(s.line, []byte("#~")) {
+		s.obsolete = true
+		s.line = s.line[2:]
+		if len(s.line) > 0 && s.line[0] == '|' {
+			s.line = append([]byte("#"), s.line...)
+		} else {
+			s.line = bytes.TrimPrefix(s.line, []byte(" "))
+		}
+	}
+	// Empty lines end a message, and are not counted in its size.
+	if len(bytes.TrimSpace(s.line)) > 0 {
+		s.msgsize += len(s.raw) + 1
+	}
+	if max := s.opts.MaxMessageSize; max > 0 && s.msgsize > max {
+		s.fail("", 0, ErrMessageTooLong)
+		s.stop()
+		return false
+	}
+	return true
 }
 
-func newScanner(r io.Reader) *scanner {
-	return &scanner{bufio.NewScanner(r), true, nil}
+// stop ends the scan: there is no current line, and no more are read.
+func (s *scanner) stop() {
+	s.eof = true
+	s.raw, s.line, s.obsolete = nil, nil, false
+}
+
+// Bytes returns the current line.
+func (s *scanner) Bytes() []byte {
+	return s.line
+}
+
+// Text returns the current line as a string.
+func (s *scanner) Text() string {
+	return string(s.line)
+}
+
+// isObsolete returns true if the current line was marked obsolete.
+func (s *scanner) isObsolete() bool {
+	return s.obsolete
 }
 
 // nextmsg goes to the next message, skipping blank lines in between.
 func (s *scanner) nextmsg() bool {
-	for {
-		if s.err != nil {
-			return false
-		}
-		if !s.Scan() {
-			return false
-		}
+	if s.lineno == 0 {
+		s.Scan()
+	}
+	for s.err == nil && !s.eof {
 		// skip newlines and lines that are precisely "#"
-		b := s.Bytes()
-		if len(bytes.TrimSpace(b)) > 1 {
+		if !s.blank() {
+			s.msgline, s.msgtext, s.fields = s.lineno, string(s.raw), s.fields[:0]
+			s.msgsize = len(s.raw) + 1
 			return true
 		}
+		// Lines between messages do not count toward the size of either.
+		s.msgsize = 0
+		s.Scan()
+	}
+	return false
+}
+
+// blank returns true if the current line is empty or precisely "#".
+func (s *scanner) blank() bool {
+	return len(bytes.TrimSpace(s.line)) <= 1
+}
+
+// comment reads the comment lines of a message, which may come in any order.
+func (s *scanner) comment() Comment {
+	var c Comment
+	for before := s.lineno; ; before = s.lineno {
+		c.TranslatorComments = append(c.TranslatorComments, s.mul("# ")...)
+		c.ExtractedComments = append(c.ExtractedComments, s.mul("#.")...)
+		c.References = append(c.References, s.spc("#:")...)
+		c.Flags = append(c.Flags, s.flags()...)
+		if s.prefix("#") && s.blank() {
+			// An empty translator comment.
+			c.TranslatorComments = append(c.TranslatorComments, "")
+			s.Scan()
+		}
+		c.PrevCtxt += s.prev("msgctxt")
+		c.PrevId += s.prev("msgid")
+		c.PrevIdPlural += s.prev("msgid_plural")
+		if s.lineno == before {
+			return c
+		}
 	}
 }
 
@@ -57,66 +161,229 @@ func (s *scanner) spc(prefix string) []string {
 	return r
 }
 
-func (s *scanner) one(prefix string) string {
-	var r string
-	if s.prefix(prefix) {
-		r = s.txt(prefix)
+// flags reads a "#," line of flags, which are separated by commas and
+// spaces, as in "#, fuzzy, c-format".
+func (s *scanner) flags() []string {
+	var r []string
+	if s.prefix("#,") {
+		r = strings.FieldsFunc(s.txt("#,"), func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
 		s.Scan()
 	}
 	return r
 }
 
+// prev reads a previous string, such as "#| msgid", which may be continued
+// on following lines starting with "#| ".
+func (s *scanner) prev(keyword string) string {
+	var prefix = "#| " + keyword
+	if !s.prefix(prefix) {
+		return ""
+	}
+	var r strings.Builder
+	r.WriteString(s.unquote(prefix, len(prefix)))
+	for s.Scan() && s.prefix(`#| "`) {
+		r.WriteString(s.unquote(prefix, len("#| ")))
+	}
+	return r.String()
+}
+
 // quo reads a quoted string after the given prefix.
 // multiline strings are handled.
 func (s *scanner) quo(prefix string) string {
-	var r string
-	if s.prefix(prefix) {
-		r = s.unquote(s.txt(prefix))
-		for {
-			if !s.Scan() {
-				return r
-			}
-			if len(s.Bytes()) > 0 && s.Bytes()[0] == '"' {
-				r += s.unquote(s.Text())
-				continue
-			}
-			break
-		}
+	if !s.prefix(prefix) {
+		return ""
 	}
-	return r
+	var field = strings.TrimSpace(prefix)
+	s.fields = append(s.fields, field)
+	var r strings.Builder
+	r.WriteString(s.unquote(field, len(prefix)))
+	for s.Scan() && len(s.Bytes()) > 0 && s.Bytes()[0] == '"' {
+		r.WriteString(s.unquote(field, 0))
+	}
+	return r.String()
 }
 
 // msgstr parses the msgstr section of a message record.
 // it handles multiline messages as well as indexed plural forms.
 func (s *scanner) msgstr() []string {
-	if s.prefix("msgstr ") {
-		return []string{s.quo("msgstr ")}
+	if s.prefix("msgstr") {
+		if s.seen("msgid_plural") {
+			s.problem("msgstr", "msgstr without index in plural message")
+		}
+		return []string{s.quo("msgstr")}
 	}
 
 	var r []string
 	for {
-		var prefix = "msgstr[" + strconv.Itoa(len(r)) + "] "
+		var prefix = "msgstr[" + strconv.Itoa(len(r)) + "]"
 		if !s.prefix(prefix) {
 			return r
 		}
+		if len(r) == 0 && !s.seen("msgid_plural") {
+			s.problem(prefix, "msgstr[0] in message without msgid_plural")
+		}
 		r = append(r, s.quo(prefix))
 	}
 }
 
-func (s *scanner) unquote(str string) string {
+// endmsg checks that the message just read is complete and that it is
+// followed by a blank line or the start of the next message. Lines that do
+// not fit are reported and skipped. It returns false if nothing could be read.
+func (s *scanner) endmsg() bool {
+	if s.lineno == s.msgline && !s.eof {
+		s.stray()
+		return false
+	}
+loop:
+	for s.err == nil && !s.eof && !s.blank() {
+		var kw = s.keyword()
+		switch {
+		case s.line[0] == '#' || kw == "msgctxt" || kw == "msgid":
+			// The start of the next message, unless it repeats a field.
+			if !s.complete() && s.seen(kw) {
+				s.problem(kw, "duplicate "+kw)
+			}
+			break loop
+		default:
+			s.stray()
+		}
+	}
+
+	if s.err == nil && !s.seen("msgid") {
+		s.msgproblem("missing msgid")
+	} else if s.err == nil && !s.complete() {
+		s.msgproblem("missing msgstr")
+	}
+	return true
+}
+
+// complete returns true if both msgid and msgstr were read.
+func (s *scanner) complete() bool {
+	return s.seen("msgid") && (s.seen("msgstr") || s.seen("msgstr[0]"))
+}
+
+// stray reports the current line as unexpected and skips it.
+func (s *scanner) stray() {
+	var kw = s.keyword()
+	switch {
+	case s.seen(kw):
+		s.problem(kw, "duplicate "+kw)
+	case strings.HasPrefix(kw, "msgstr[") && s.seen("msgstr[0]"):
+		s.problem(kw, "non-contiguous msgstr index "+kw)
+	case kw == "msgctxt" || kw == "msgid" || kw == "msgid_plural" ||
+		kw == "msgstr" || strings.HasPrefix(kw, "msgstr["):
+		s.problem(kw, "misplaced "+kw)
+	case s.line[0] == '"':
+		s.problem("", "unexpected string")
+	case s.line[0] == '#':
+		s.problem("", "unknown comment "+strconv.Quote(kw))
+	default:
+		s.problem("", "unknown keyword "+strconv.Quote(kw))
+	}
+	s.Scan()
+}
+
+// keyword returns the first word on the current line.
+func (s *scanner) keyword() string {
+	var line = s.Text()
+	if i := strings.IndexAny(line, " \t\""); i != -1 {
+		return line[:i]
+	}
+	return line
+}
+
+// seen returns true if the given field was read in the current message.
+func (s *scanner) seen(field string) bool {
+	for _, f := range s.fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// problem reports a problem with the current line. It is an error in strict
+// mode, and a warning otherwise.
+func (s *scanner) problem(field, msg string) {
+	s.report(&ParseError{
+		Filename: s.opts.Filename,
+		Line:     s.lineno,
+		Column:   len(s.raw) - len(s.line) + 1,
+		Text:     string(s.raw),
+		Field:    field,
+		Err:      errors.New(msg),
+	})
+}
+
+// msgproblem reports a problem with the current message as a whole.
+func (s *scanner) msgproblem(msg string) {
+	s.report(&ParseError{
+		Filename: s.opts.Filename,
+		Line:     s.msgline,
+		Column:   1,
+		Text:     s.msgtext,
+		Err:      errors.New(msg),
+	})
+}
+
+func (s *scanner) report(err *ParseError) {
+	if s.opts.Strict {
+		if s.err == nil {
+			s.err = err
+		}
+	} else if s.opts.Warn != nil {
+		s.opts.Warn(err)
+	}
+}
+
+// unquote unquotes the string on the current line that begins at offset start,
+// recording an error for the given field if it is malformed.
+func (s *scanner) unquote(field string, start int) string {
+	var text = s.Text()[start:]
+	var str = strings.TrimLeft(text, " \t")
+	start += len(text) - len(str)
+	str = strings.TrimRight(str, " \t\r")
 	var r, err = strconv.Unquote(str)
 	if err != nil {
-		s.err = err
+		s.fail(field, start+unquoteErrPos(str), err)
 	}
 	return r
 }
 
-// Err returns the last error encountered, if any.
+// fail records an error at the given offset within the current line, unless
+// one was recorded already.
+func (s *scanner) fail(field string, offset int, err error) {
+	if s.err != nil {
+		return
+	}
+	// Account for an obsolete marker that was stripped from the line.
+	offset += len(s.raw) - len(s.line)
+	s.err = &ParseError{
+		Filename: s.opts.Filename,
+		Line:     s.lineno,
+		Column:   offset + 1,
+		Text:     string(s.raw),
+		Field:    field,
+		Err:      err,
+	}
+}
+
+// Err returns the first error encountered, if any.
 func (s *scanner) Err() error {
 	if s.err != nil {
 		return s.err
 	}
-	return s.Scanner.Err()
+	if err := s.Scanner.Err(); err != nil {
+		return &ParseError{
+			Filename: s.opts.Filename,
+			Line:     s.lineno + 1,
+			Column:   1,
+			Err:      err,
+		}
+	}
+	return nil
 }
 
 // txt returns the text on the current line after the given prefix, trimming space.
@@ -125,6 +392,18 @@ func (s *scanner) txt(prefix string) string {
 }
 
 // prefix returns true if the current line begins with the given prefix.
+// A prefix ending in a keyword only matches the whole keyword, followed by
+// whitespace or a string, so that "msgid" does not match "msgid_plural".
 func (s *scanner) prefix(prefix string) bool {
-	return bytes.HasPrefix(s.Bytes(), []byte(prefix))
+	if !bytes.HasPrefix(s.line, []byte(prefix)) {
+		return false
+	}
+	if c := prefix[len(prefix)-1]; (c >= 'a' && c <= 'z' || c == ']') && len(s.line) > len(prefix) {
+		switch s.line[len(prefix)] {
+		case ' ', '\t', '"':
+		default:
+			return false
+		}
+	}
+	return true
 }
@@ -1,50 +1,166 @@
 package po
 
 import (
-	"bytes"
+	"bufio"
 	"io"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-// writer formats message fields into a buffer and writes to a destination.
+// DefaultWrapWidth is the line width used by the GNU gettext tools.
+const DefaultWrapWidth = 79
+
+// WriteOptions controls the formatting of PO files.
+type WriteOptions struct {
+	// Width is the maximum width of a line, DefaultWrapWidth if zero.
+	// Strings and references are wrapped at spaces to fit, as msgcat does.
+	Width int
+
+	// NoWrap disables wrapping, like the --no-wrap option of the GNU tools.
+	// Strings are still broken into lines after embedded newlines.
+	NoWrap bool
+}
+
+// width returns the maximum line width, or -1 if lines are not wrapped.
+func (opts WriteOptions) width() int {
+	switch {
+	case opts.NoWrap:
+		return -1
+	case opts.Width <= 0:
+		return DefaultWrapWidth
+	}
+	return opts.Width
+}
+
+// writer formats message fields and writes them to a destination.
 // it is a mirror of the scanner.
+// Once a write fails, later writes are skipped and err holds the error.
 type writer struct {
-	buf *bytes.Buffer
-	n   int64
+	w     io.Writer
+	n     int64 // number of bytes written
+	err   error
+	lead  string        // written before every quoted line, e.g. "#~ " for obsolete messages
+	width int           // maximum line width, or -1 for no wrapping
+	buf   *bufio.Writer // if set, the buffer of w, flushed by result
+
+	// nplurals is the number of msgstr[n] written for untranslated plural
+	// messages, if known.
+	nplurals int
+}
+
+func newWriter(w io.Writer, opts WriteOptions) writer {
+	return writer{w: w, width: opts.width()}
 }
 
-func newWriter() writer {
-	return writer{new(bytes.Buffer), 0}
+// newBufferedWriter returns a writer that buffers its output to w, so that
+// the many small writes of the fields do not each reach w.
+func newBufferedWriter(w io.Writer, opts WriteOptions) writer {
+	var buf = bufio.NewWriter(w)
+	var wr = newWriter(buf, opts)
+	wr.buf = buf
+	return wr
+}
+
+// write writes the string to the destination, unless a write failed already.
+func (wr *writer) write(s string) {
+	if wr.err != nil {
+		return
+	}
+	var n int
+	n, wr.err = io.WriteString(wr.w, s)
+	wr.n += int64(n)
+}
+
+// header writes the header entry, with its comments.
+func (wr *writer) header(h Header, c Comment) {
+	wr.comment(c, "#| ")
+	wr.quo("msgid ", "")
+	wr.quo("msgstr ", h.String())
+}
+
+// message writes a message, with its comments.
+func (wr *writer) message(m Message) {
+	if m.Obsolete {
+		wr.comment(m.Comment, "#~| ")
+		wr.lead = "#~ "
+		defer func() { wr.lead = "" }()
+	} else {
+		wr.comment(m.Comment, "#| ")
+	}
+	wr.opt("msgctxt ", m.Ctxt)
+	wr.quo("msgid ", m.Id)
+	wr.opt("msgid_plural ", m.IdPlural)
+	if len(m.IdPlural) == 0 {
+		wr.msgstr(m.Str)
+	} else {
+		wr.plural(m.Str)
+	}
+}
+
+// comment writes the comment lines, using prev as the marker for previous
+// strings ("#| ", or "#~| " for obsolete messages).
+func (wr *writer) comment(c Comment, prev string) {
+	wr.mul("#", c.TranslatorComments)
+	wr.mul("#.", c.ExtractedComments)
+	wr.refs(c.References)
+	wr.flags(c.Flags)
+	wr.prev(prev, "msgctxt ", c.PrevCtxt)
+	wr.prev(prev, "msgid ", c.PrevId)
+	wr.prev(prev, "msgid_plural ", c.PrevIdPlural)
 }
 
 // mul writes the given values on multiple lines, one per line.
+// Non-empty values are separated from the prefix by a space.
 func (wr *writer) mul(prefix string, vals []string) {
 	for _, val := range vals {
-		wr.buf.WriteString(prefix + val + "\n")
+		if val == "" {
+			wr.write(prefix + "\n")
+		} else {
+			wr.write(prefix + " " + val + "\n")
+		}
 	}
 }
 
-// spc writes the given values on a single line, separated by spaces.
-func (wr *writer) spc(prefix string, vals []string) {
-	if len(vals) == 0 {
+// flags writes the flags on a single line, separated by commas as msgmerge
+// does: "#, fuzzy, c-format".
+func (wr *writer) flags(flags []string) {
+	if len(flags) == 0 {
 		return
 	}
-	wr.buf.WriteString(prefix)
-	for i, val := range vals {
-		if i > 0 {
-			wr.buf.WriteString(" ")
+	wr.write("#, " + strings.Join(flags, ", ") + "\n")
+}
+
+// refs writes the references, as many per line as fit.
+func (wr *writer) refs(refs []string) {
+	if len(refs) == 0 {
+		return
+	}
+	var col = 0
+	for _, ref := range refs {
+		var n = utf8.RuneCountInString(ref) + 1
+		if col == 0 || wr.width >= 0 && col+n > wr.width {
+			if col > 0 {
+				wr.write("\n")
+			}
+			wr.write("#:")
+			col = 2
 		}
-		wr.buf.WriteString(val)
+		wr.write(" " + ref)
+		col += n
 	}
-	wr.buf.WriteString("\n")
+	wr.write("\n")
 }
 
-// one writes the given value with the given prefix.
-func (wr *writer) one(prefix, val string) {
-	if val != "" {
-		wr.buf.WriteString(prefix + val + "\n")
+// prev writes a previous string, if present, marking every line with lead.
+func (wr *writer) prev(lead, prefix, val string) {
+	if val == "" {
+		return
 	}
+	var saved = wr.lead
+	wr.lead = lead
+	wr.quo(prefix, val)
+	wr.lead = saved
 }
 
 // opt writes the given value as a quoted string
@@ -55,26 +171,66 @@ func (wr *writer) opt(prefix, val string) {
 }
 
 // quo always writes the given value (quoted), even if empty.
-// Additionally, it breaks multiline strings across lines.
+// Strings that contain newlines before their end or do not fit on the line
+// are written as an empty string followed by one line per segment, wrapped
+// at spaces to fit within the width.
 func (wr *writer) quo(prefix, val string) {
-	if !strings.Contains(val, "\n") {
-		wr.buf.WriteString(prefix + strconv.Quote(val) + "\n")
+	var first = wr.lead + prefix + strconv.Quote(val)
+	if !strings.Contains(strings.TrimSuffix(val, "\n"), "\n") &&
+		(wr.width < 0 || utf8.RuneCountInString(first) <= wr.width) {
+		wr.write(first + "\n")
 		return
 	}
 
 	// multiline
-	wr.buf.WriteString(prefix + `""` + "\n")
-	for {
-		i := strings.Index(val, "\n")
-		if i == -1 {
-			if val != "" {
-				wr.buf.WriteString(strconv.Quote(val) + "\n")
+	wr.write(wr.lead + prefix + `""` + "\n")
+	for _, line := range wrap(val, wr.width-len(wr.lead)-2) {
+		wr.write(wr.lead + `"` + line + `"` + "\n")
+	}
+}
+
+// wrap breaks val into escaped lines, after each newline and at spaces so
+// that each line is at most width characters long where possible.
+// A negative width only breaks after newlines.
+func wrap(val string, width int) []string {
+	var lines []string
+	for val != "" {
+		var segment = val
+		if i := strings.Index(val, "\n"); i != -1 {
+			segment = val[:i+1]
+		}
+		val = val[len(segment):]
+
+		var line string
+		for segment != "" {
+			// The next word, including any spaces following it.
+			var word = segment
+			if i := strings.IndexByte(segment, ' '); i != -1 {
+				var j = i
+				for j < len(segment) && segment[j] == ' ' {
+					j++
+				}
+				word = segment[:j]
 			}
-			return
+			segment = segment[len(word):]
+
+			var esc = quote(word)
+			if line != "" && width >= 0 &&
+				utf8.RuneCountInString(line)+utf8.RuneCountInString(esc) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += esc
 		}
-		wr.buf.WriteString(strconv.Quote(val[:i+1]) + "\n")
-		val = val[i+1:]
+		lines = append(lines, line)
 	}
+	return lines
+}
+
+// quote returns the escaped form of s, without the surrounding quotes.
+func quote(s string) string {
+	var q = strconv.Quote(s)
+	return q[1 : len(q)-1]
 }
 
 // msgstr writes a singular msgstr.
@@ -88,28 +244,37 @@ func (wr *writer) msgstr(vals []string) {
 }
 
 // plural writes the plural form of msgstr.
+// Untranslated messages are padded to nplurals entries.
 func (wr *writer) plural(vals []string) {
-	if len(vals) == 0 {
-		wr.quo("msgstr[0] ", "")
-	} else {
-		for i, str := range vals {
-			wr.quo("msgstr["+strconv.Itoa(i)+"] ", str)
+	var n = len(vals)
+	if n < wr.nplurals && !(Message{Str: vals}).hasTranslation() {
+		n = wr.nplurals
+	}
+	if n == 0 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		var str string
+		if i < len(vals) {
+			str = vals[i]
 		}
+		wr.quo("msgstr["+strconv.Itoa(i)+"] ", str)
 	}
 }
 
 // newline writes a newline
 func (wr *writer) newline() {
-	wr.buf.WriteString("\n")
-}
-
-// from writes to this buffer from the given WriterTo.
-// it ignores errors, as writes to bytes.Buffer do not return errors.
-func (wr *writer) from(w io.WriterTo) {
-	w.WriteTo(wr.buf)
+	wr.write("\n")
 }
 
-// to writes the contents of the writer to the given output.
-func (wr *writer) to(w io.Writer) (n int64, err error) {
-	return io.Copy(w, wr.buf)
+// result returns the number of bytes written and the first error, if any.
+func (wr *writer) result() (n int64, err error) {
+	if wr.buf != nil {
+		if err = wr.buf.Flush(); wr.err == nil {
+			wr.err = err
+		}
+		// Bytes left in the buffer after a failed write did not reach w.
+		wr.n -= int64(wr.buf.Buffered())
+	}
+	return wr.n, wr.err
 }
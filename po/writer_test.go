@@ -0,0 +1,211 @@
+package po
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var wrappedPo = `
+# Translator comment
+#
+# after an empty line
+#: src/first_file.go:10 src/second_file.go:20 src/third_file.go:30
+#: src/fourth_file.go:40
+#, fuzzy
+#| msgid ""
+#| "This was a long message that needed to be wrapped because it was longer "
+#| "than seventy-nine characters."
+msgid ""
+"This is a long message that will need to be wrapped because it is longer "
+"than seventy-nine characters, so it spans lines."
+msgstr "Short\n"
+
+#~ msgid ""
+#~ "An obsolete message that will need to be wrapped because it is longer "
+#~ "than seventy-nine characters."
+#~ msgstr ""
+
+`[1:]
+
+func TestWriteWrapped(t *testing.T) {
+	var f, err = ParseWithOptions(strings.NewReader(wrappedPo), ParseOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg = f.Messages[0]
+	if msg.PrevId != "This was a long message that needed to be wrapped because it was longer than seventy-nine characters." {
+		t.Errorf("unexpected PrevId %q", msg.PrevId)
+	}
+	if len(msg.TranslatorComments) != 3 || len(msg.References) != 4 {
+		t.Errorf("unexpected comments %q", msg.Comment)
+	}
+
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != wrappedPo {
+		t.Errorf("expected:\n%v\ngot:\n%v", wrappedPo, buf.String())
+	}
+}
+
+func TestWriteNoWrap(t *testing.T) {
+	var msg = Message{
+		Comment: Comment{References: []string{"src/first_file.go:10", "src/second_file.go:20",
+			"src/third_file.go:30", "src/fourth_file.go:40"}},
+		Id:  "This is a long message that would be wrapped because it is longer than seventy-nine characters.",
+		Str: []string{"First line\nSecond line"},
+	}
+	var expected = `#: src/first_file.go:10 src/second_file.go:20 src/third_file.go:30 src/fourth_file.go:40
+msgid "This is a long message that would be wrapped because it is longer than seventy-nine characters."
+msgstr ""
+"First line\n"
+"Second line"
+`
+	var buf bytes.Buffer
+	if _, err := msg.WriteWithOptions(&buf, WriteOptions{NoWrap: true}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+
+	expected = `#: src/first_file.go:10
+#: src/second_file.go:20
+#: src/third_file.go:30
+#: src/fourth_file.go:40
+msgid ""
+"This is a long "
+"message that "
+"would be wrapped "
+"because it is "
+"longer than "
+"seventy-nine "
+"characters."
+msgstr ""
+"First line\n"
+"Second line"
+`
+	buf.Reset()
+	if _, err := msg.WriteWithOptions(&buf, WriteOptions{Width: 20}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+}
+
+func TestWritePluralForms(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`msgid ""
+msgstr "Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+msgid "%d dir"
+msgid_plural "%d dirs"
+msgstr[0] "%d adresár"
+msgstr[1] "%d adresáre"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.PluralRule == nil || f.PluralRule.NPlurals != 3 {
+		t.Fatalf("unexpected plural rule %v", f.PluralRule)
+	}
+
+	var expected = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+msgid "%d dir"
+msgid_plural "%d dirs"
+msgstr[0] "%d adresár"
+msgstr[1] "%d adresáre"
+
+`
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	// The Encoder takes the plural rule from the header.
+	buf.Reset()
+	var enc = NewEncoder(&buf)
+	enc.WriteHeader(f.Header)
+	for _, msg := range f.Messages {
+		enc.WriteMessage(msg)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestPrevUnquoted(t *testing.T) {
+	var po = `#, fuzzy
+#| msgctxt "old\tctx"
+#| msgid ""
+#| "Old \"text\"\n"
+#| "continued"
+#| msgid_plural ""
+#| "Old texts that were long enough to be wrapped at spaces like the other "
+#| "strings"
+msgid "New"
+msgid_plural "News"
+msgstr[0] ""
+msgstr[1] ""
+
+`
+	var f, err = Parse(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg = f.Messages[0]
+	if msg.PrevCtxt != "old\tctx" || msg.PrevId != "Old \"text\"\ncontinued" || msg.PrevIdPlural != "Old texts that were long enough to be wrapped at spaces like the other strings" {
+		t.Errorf("expected unquoted previous strings, got %q %q %q", msg.PrevCtxt, msg.PrevId, msg.PrevIdPlural)
+	}
+
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != po {
+		t.Errorf("expected:\n%v\ngot:\n%v", po, buf.String())
+	}
+}
+
+// writeCounter counts the calls to Write.
+type writeCounter struct {
+	calls int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.calls++
+	return len(p), nil
+}
+
+func TestWriteBuffered(t *testing.T) {
+	var f = File{Header: Header{{"Language", "sk"}}}
+	for i := 0; i < 100; i++ {
+		f.Messages = append(f.Messages, Message{Id: "Hello", Str: []string{"Ahoj"}})
+	}
+	var w writeCounter
+	if _, err := f.WriteTo(&w); err != nil {
+		t.Fatal(err)
+	}
+	if w.calls != 1 {
+		t.Errorf("expected 1 write, got %v", w.calls)
+	}
+}